@@ -7,7 +7,7 @@ import (
 )
 
 // amt takes 32 + len(entries) * 8 bytes on 64bit archs
-type amt[K comparable, V any] struct {
+type amt[K any, V any] struct {
 	bits    uint32      // 8 bytes on 64bit archs
 	entries []*entry[V] // 24 + len(entries) * 8 bytes on 64bit archs
 }
@@ -35,6 +35,23 @@ func present(bitmap uint32, bitpos uint32) bool {
 	return bitmap&bitpos != 0
 }
 
+// equals compares keys using the '==' operator.
+func equals[K comparable](a, b K) bool {
+	return a == b
+}
+
+// refKey returns the key stored in the ref of an entry. A nil key of an
+// interface type K is stored as a nil ref and returned as the zero K.
+func refKey[K any](ref any) K {
+	key, _ := ref.(K)
+	return key
+}
+
+// hashOf hashes keys using the internal hash function.
+func hashOf[K any](key K) uint32 {
+	return hash(key)
+}
+
 func (n amt[K, V]) len() int {
 	len := len(n.entries)
 	for _, e := range n.entries {
@@ -58,7 +75,9 @@ func (n amt[K, V]) depth() int {
 	return 1 + depth
 }
 
-func (n amt[K, V]) lookup(prefix uint32, shift uint8, key K) (V, bool) {
+// lookup returns the value for the key along with the value true when it is
+// present. Keys are compared with the equal function.
+func (n amt[K, V]) lookup(prefix uint32, shift uint8, key K, equal func(K, K) bool) (V, bool) {
 	for {
 		bitpos := bitpos(prefix, shift)
 		if present(n.bits, bitpos) {
@@ -68,12 +87,12 @@ func (n amt[K, V]) lookup(prefix uint32, shift uint8, key K) (V, bool) {
 				shift += nextlevel
 				continue
 			}
-			if e.prefix == prefix && e.ref == key {
+			if e.prefix == prefix && equal(refKey[K](e.ref), key) {
 				return e.value, true
 			}
 		} else if shift == collision {
 			for _, e := range n.entries {
-				if e.prefix == prefix && e.ref == key {
+				if e.prefix == prefix && equal(refKey[K](e.ref), key) {
 					return e.value, true
 				}
 			}
@@ -83,16 +102,19 @@ func (n amt[K, V]) lookup(prefix uint32, shift uint8, key K) (V, bool) {
 	}
 }
 
-func (n amt[K, V]) foreach(f func(K, V) bool) {
+func (n amt[K, V]) foreach(f func(K, V) bool) bool {
 	for _, e := range n.entries {
 		if a, ok := e.ref.(amt[K, V]); ok {
-			a.foreach(f)
+			if !a.foreach(f) {
+				return false
+			}
 		} else {
-			if !f(e.ref.(K), e.value) {
-				return
+			if !f(refKey[K](e.ref), e.value) {
+				return false
 			}
 		}
 	}
+	return true
 }
 
 func (n amt[K, V]) string() string {
@@ -108,7 +130,9 @@ func (n amt[K, V]) string() string {
 	return b.String()
 }
 
-func (n amt[K, V]) set(prefix uint32, shift uint8, key K, value V) amt[K, V] {
+// set returns a copy of n with the key,value pair inserted. Keys are
+// compared with the equal function.
+func (n amt[K, V]) set(prefix uint32, shift uint8, key K, value V, equal func(K, K) bool) amt[K, V] {
 	bitpos := bitpos(prefix, shift)
 	if present(n.bits, bitpos) {
 		index := index(n.bits, bitpos)
@@ -117,15 +141,15 @@ func (n amt[K, V]) set(prefix uint32, shift uint8, key K, value V) amt[K, V] {
 		n.entries = entries
 		e := n.entries[index]
 		if a, ok := e.ref.(amt[K, V]); ok {
-			n.entries[index] = &entry[V]{ref: a.set(prefix, shift+nextlevel, key, value)}
+			n.entries[index] = &entry[V]{ref: a.set(prefix, shift+nextlevel, key, value, equal)}
 		} else {
-			if e.prefix == prefix && e.ref == key {
+			if e.prefix == prefix && equal(refKey[K](e.ref), key) {
 				n.entries[index] = &entry[V]{prefix, value, key}
 			} else {
 				// replace item with a new amt node holding the 2 items
 				n.entries[index] = &entry[V]{ref: amt[K, V]{}.
-					set(e.prefix, shift+nextlevel, e.ref.(K), e.value).
-					set(prefix, shift+nextlevel, key, value)}
+					set(e.prefix, shift+nextlevel, refKey[K](e.ref), e.value, equal).
+					set(prefix, shift+nextlevel, key, value, equal)}
 			}
 		}
 	} else if shift < collision {
@@ -141,7 +165,7 @@ func (n amt[K, V]) set(prefix uint32, shift uint8, key K, value V) amt[K, V] {
 		copy(entries, n.entries)
 		n.entries = entries
 		for index, e := range n.entries {
-			if e.ref == key {
+			if equal(refKey[K](e.ref), key) {
 				n.entries[index] = &entry[V]{prefix, value, key}
 				return n
 			}
@@ -151,7 +175,9 @@ func (n amt[K, V]) set(prefix uint32, shift uint8, key K, value V) amt[K, V] {
 	return n
 }
 
-func (n amt[K, V]) delete(prefix uint32, shift uint8, key K) amt[K, V] {
+// delete returns a copy of n with the entry for the key removed. Keys are
+// compared with the equal function.
+func (n amt[K, V]) delete(prefix uint32, shift uint8, key K, equal func(K, K) bool) amt[K, V] {
 	bitpos := bitpos(prefix, shift)
 	if present(n.bits, bitpos) {
 		index := index(n.bits, bitpos)
@@ -159,14 +185,14 @@ func (n amt[K, V]) delete(prefix uint32, shift uint8, key K) amt[K, V] {
 		if a, ok := e.ref.(amt[K, V]); ok {
 			entries := make([]*entry[V], len(n.entries))
 			copy(entries, n.entries)
-			if a = a.delete(prefix, shift+nextlevel, key); a.len() == 1 {
+			if a = a.delete(prefix, shift+nextlevel, key, equal); a.len() == 1 {
 				entries[index] = a.entries[0]
 			} else {
 				entries[index] = &entry[V]{ref: a}
 			}
 			n.entries = entries
 		} else {
-			if e.prefix == prefix && e.ref == key {
+			if e.prefix == prefix && equal(refKey[K](e.ref), key) {
 				if index+1 == len(n.entries) {
 					n.entries = n.entries[:index]
 				} else {
@@ -180,7 +206,7 @@ func (n amt[K, V]) delete(prefix uint32, shift uint8, key K) amt[K, V] {
 		}
 	} else if shift == collision {
 		for index, e := range n.entries {
-			if e.prefix == prefix && e.ref == key {
+			if e.prefix == prefix && equal(refKey[K](e.ref), key) {
 				if index+1 == len(n.entries) {
 					n.entries = n.entries[:index]
 				} else {
@@ -0,0 +1,206 @@
+package immutable
+
+import (
+	"reflect"
+	"sync/atomic"
+)
+
+// atomConfig holds the validator and the watches of an Atom. It is replaced
+// as a whole whenever either of them changes.
+type atomConfig[T any] struct {
+	validator func(T) error
+	watches   []func(old, new T)
+}
+
+// Atom is a concurrency-safe reference to an immutable value such as a Map,
+// Set or Store. Load never blocks, updates replace the value atomically and
+// Swap retries its update function until it wins against concurrent
+// updates. An optional validator can reject new values and watches are
+// called after every successful update with the old and the new value.
+// The zero value of an Atom holds the zero value of T.
+type Atom[T any] struct {
+	value  atomic.Pointer[T]
+	config atomic.Pointer[atomConfig[T]]
+}
+
+// NewAtom returns a new Atom holding the given value.
+func NewAtom[T any](value T) *Atom[T] {
+	a := &Atom[T]{}
+	a.value.Store(&value)
+	return a
+}
+
+func (a *Atom[T]) load() (*T, T) {
+	p := a.value.Load()
+	if p == nil {
+		var zero T
+		return nil, zero
+	}
+	return p, *p
+}
+
+// Load returns the current value.
+func (a *Atom[T]) Load() T {
+	_, value := a.load()
+	return value
+}
+
+// validate returns the configuration to use for an update to value or the
+// error returned by the validator.
+func (a *Atom[T]) validate(value T) (*atomConfig[T], error) {
+	c := a.config.Load()
+	if c != nil && c.validator != nil {
+		if err := c.validator(value); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (c *atomConfig[T]) notify(old, new T) {
+	if c != nil {
+		for _, f := range c.watches {
+			f(old, new)
+		}
+	}
+}
+
+// Store replaces the current value with the given value. It returns the
+// error of the validator when it rejects the value.
+func (a *Atom[T]) Store(value T) error {
+	c, err := a.validate(value)
+	if err != nil {
+		return err
+	}
+	p := a.value.Swap(&value)
+	var old T
+	if p != nil {
+		old = *p
+	}
+	c.notify(old, value)
+	return nil
+}
+
+// CompareAndSwap replaces the current value with new when the current value
+// is identical to old and returns true. Values are identical when they are
+// made from the same memory, e.g. when new was derived from a value obtained
+// from Load and old is that same value. A Map and the copy returned by Set
+// are never identical, even when Set stored an equal value. CompareAndSwap
+// returns the error of the validator when it rejects the new value.
+func (a *Atom[T]) CompareAndSwap(old, new T) (bool, error) {
+	c, err := a.validate(new)
+	if err != nil {
+		return false, err
+	}
+	for {
+		p, current := a.load()
+		if !identical(reflect.ValueOf(&current).Elem(), reflect.ValueOf(&old).Elem()) {
+			return false, nil
+		}
+		if a.value.CompareAndSwap(p, &new) {
+			c.notify(current, new)
+			return true, nil
+		}
+	}
+}
+
+// Swap replaces the current value with the result of calling f with the
+// current value and returns the new value. When another goroutine updates
+// the value while f is running, f is called again with the updated value,
+// so f must be free of side effects. Swap returns the error of the
+// validator when it rejects the result of f, leaving the value unchanged.
+func (a *Atom[T]) Swap(f func(T) T) (T, error) {
+	for {
+		p, current := a.load()
+		next := f(current)
+		c, err := a.validate(next)
+		if err != nil {
+			return current, err
+		}
+		if a.value.CompareAndSwap(p, &next) {
+			c.notify(current, next)
+			return next, nil
+		}
+	}
+}
+
+// SetValidator sets the function that checks every new value before it is
+// stored. A validator that returns an error rejects the value. Passing nil
+// removes the validator. The current value is not checked.
+func (a *Atom[T]) SetValidator(validator func(T) error) {
+	for {
+		c := a.config.Load()
+		next := &atomConfig[T]{validator: validator}
+		if c != nil {
+			next.watches = c.watches
+		}
+		if a.config.CompareAndSwap(c, next) {
+			return
+		}
+	}
+}
+
+// Watch adds a function that is called with the old and the new value after
+// every successful update. Watches are called in the order in which they
+// were added, on the goroutine that performed the update.
+func (a *Atom[T]) Watch(watch func(old, new T)) {
+	for {
+		c := a.config.Load()
+		next := &atomConfig[T]{}
+		if c != nil {
+			next.validator = c.validator
+			next.watches = append(clone(c.watches), watch)
+		} else {
+			next.watches = []func(T, T){watch}
+		}
+		if a.config.CompareAndSwap(c, next) {
+			return
+		}
+	}
+}
+
+// identical returns true when the values a and b of the same type are made
+// from the same memory. Slices are identical when they share their backing
+// array and length, maps, channels, functions and pointers when they point
+// to the same address. The values that are referenced are not compared.
+func identical(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Bool:
+		return a.Bool() == b.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() == b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() == b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() == b.Float()
+	case reflect.Complex64, reflect.Complex128:
+		return a.Complex() == b.Complex()
+	case reflect.String:
+		return a.String() == b.String()
+	case reflect.Slice:
+		return a.Pointer() == b.Pointer() && a.Len() == b.Len()
+	case reflect.Map, reflect.Chan, reflect.Func, reflect.Pointer, reflect.UnsafePointer:
+		return a.Pointer() == b.Pointer()
+	case reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() && b.IsNil()
+		}
+		a, b = a.Elem(), b.Elem()
+		return a.Type() == b.Type() && identical(a, b)
+	case reflect.Array:
+		for i := 0; i < a.Len(); i++ {
+			if !identical(a.Index(i), b.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if !identical(a.Field(i), b.Field(i)) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package immutable
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestAtom(t *testing.T) {
+	a := NewAtom(Map[string, int]{})
+	var watched atomic.Int64
+	a.Watch(func(old, new Map[string, int]) {
+		assert.EqualInt(t, old.Get("n")+1, new.Get("n"), "watch")
+		watched.Add(1)
+	})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				a.Swap(func(m Map[string, int]) Map[string, int] {
+					return m.Set("n", m.Get("n")+1)
+				})
+			}
+		}()
+	}
+	wg.Wait()
+	assert.EqualInt(t, 800, a.Load().Get("n"), "a.Load().Get(n)")
+	assert.EqualInt(t, 800, int(watched.Load()), "watched")
+}
+
+func TestAtomCompareAndSwap(t *testing.T) {
+	var a Atom[Map[string, int]]
+	m0 := a.Load()
+	m1 := m0.Set("a", 1)
+	ok, err := a.CompareAndSwap(m0, m1)
+	assert.Equal(t, true, ok, "a.CompareAndSwap(m0, m1)")
+	assert.Equal(t, nil, err, "a.CompareAndSwap(m0, m1) err")
+
+	ok, _ = a.CompareAndSwap(m0, m0.Set("b", 2))
+	assert.Equal(t, false, ok, "a.CompareAndSwap(m0, ...)")
+	ok, _ = a.CompareAndSwap(m1.Set("a", 1), m0)
+	assert.Equal(t, false, ok, "a.CompareAndSwap(m1.Set(a, 1), m0)")
+	ok, _ = a.CompareAndSwap(a.Load(), m0)
+	assert.Equal(t, true, ok, "a.CompareAndSwap(a.Load(), m0)")
+	assert.EqualInt(t, 0, a.Load().Len(), "a.Load().Len()")
+}
+
+func TestAtomValidator(t *testing.T) {
+	tooLarge := errors.New("too large")
+	a := NewAtom(Set[int]{})
+	a.SetValidator(func(s Set[int]) error {
+		if s.Len() > 2 {
+			return tooLarge
+		}
+		return nil
+	})
+	assert.Equal(t, nil, a.Store(a.Load().Put(1).Put(2)), "a.Store({1, 2})")
+	_, err := a.Swap(func(s Set[int]) Set[int] { return s.Put(3) })
+	assert.Equal(t, tooLarge, err, "a.Swap(Put(3))")
+	_, err = a.CompareAndSwap(a.Load(), a.Load().Put(3))
+	assert.Equal(t, tooLarge, err, "a.CompareAndSwap(Put(3))")
+	assert.EqualInt(t, 2, a.Load().Len(), "a.Load().Len()")
+
+	a.SetValidator(nil)
+	assert.Equal(t, nil, a.Store(a.Load().Put(3)), "a.Store({1, 2, 3})")
+}
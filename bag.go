@@ -0,0 +1,117 @@
+package immutable
+
+// Bag is a persistent immutable multiset that counts the number of times
+// every key was added to it. It is built on a Map from keys to counts, keys
+// with a count of zero are not present in the Map.
+type Bag[K comparable] struct {
+	counts Map[K, int]
+	len    int
+}
+
+// Len returns the total number of keys in the bag, counting every key as
+// many times as it was added.
+func (a Bag[K]) Len() int {
+	return a.len
+}
+
+// Distinct returns the number of distinct keys in the bag.
+func (a Bag[K]) Distinct() int {
+	return a.counts.Len()
+}
+
+// Count returns the number of times the key is present in the bag.
+func (a Bag[K]) Count(key K) int {
+	return a.counts.Get(key)
+}
+
+// Has returns true when the key is present at least once.
+func (a Bag[K]) Has(key K) bool {
+	return a.counts.Has(key)
+}
+
+// Range calls the given function for every key present along with its count.
+func (a Bag[K]) Range(f func(K, int) bool) {
+	a.counts.Range(f)
+}
+
+// String returns a string representation of the keys and their counts.
+func (a Bag[K]) String() string {
+	return a.counts.String()
+}
+
+// with returns a copy of the bag with the count for the key changed.
+func (a Bag[K]) with(key K, count int) Bag[K] {
+	len := a.len - a.counts.Get(key) + count
+	if count <= 0 {
+		return Bag[K]{a.counts.Del(key), len}
+	}
+	return Bag[K]{a.counts.Set(key, count), len}
+}
+
+// Add returns a copy of the bag with the key added n times. Adding a count
+// that is not positive returns the bag unchanged.
+func (a Bag[K]) Add(key K, n int) Bag[K] {
+	if n <= 0 {
+		return a
+	}
+	return a.with(key, a.counts.Get(key)+n)
+}
+
+// Remove returns a copy of the bag with the key removed n times. The key is
+// removed from the bag completely when its count drops to zero.
+func (a Bag[K]) Remove(key K, n int) Bag[K] {
+	count, ok := a.counts.Lookup(key)
+	if !ok || n <= 0 {
+		return a
+	}
+	if n > count {
+		n = count
+	}
+	return a.with(key, count-n)
+}
+
+// Union returns a bag with every key counted the maximum number of times it
+// is present in either bag.
+func (a Bag[K]) Union(b Bag[K]) Bag[K] {
+	b.counts.Range(func(key K, count int) bool {
+		if count > a.counts.Get(key) {
+			a = a.with(key, count)
+		}
+		return true
+	})
+	return a
+}
+
+// Sum returns a bag with every key counted the number of times it is
+// present in a plus the number of times it is present in b.
+func (a Bag[K]) Sum(b Bag[K]) Bag[K] {
+	b.counts.Range(func(key K, count int) bool {
+		a = a.Add(key, count)
+		return true
+	})
+	return a
+}
+
+// Intersection returns a bag with every key counted the minimum number of
+// times it is present in either bag.
+func (a Bag[K]) Intersection(b Bag[K]) Bag[K] {
+	var r Bag[K]
+	a.counts.Range(func(key K, count int) bool {
+		if other := b.counts.Get(key); other < count {
+			count = other
+		}
+		r = r.Add(key, count)
+		return true
+	})
+	return r
+}
+
+// Difference returns a bag with every key of a counted the number of times
+// it is present in a minus the number of times it is present in b.
+func (a Bag[K]) Difference(b Bag[K]) Bag[K] {
+	b.counts.Range(func(key K, count int) bool {
+		a = a.Remove(key, count)
+		return true
+	})
+	return a
+}
@@ -0,0 +1,41 @@
+package immutable
+
+import (
+	"testing"
+)
+
+func TestBag(t *testing.T) {
+	var b0 Bag[string]
+	b1 := b0.Add("a", 3).Add("b", 1).Add("a", 1).Add("c", 0)
+
+	assert.EqualInt(t, 5, b1.Len(), "b1.Len()")
+	assert.EqualInt(t, 2, b1.Distinct(), "b1.Distinct()")
+	assert.EqualInt(t, 4, b1.Count("a"), "b1.Count(a)")
+	assert.Equal(t, false, b1.Has("c"), "b1.Has(c)")
+
+	b2 := b1.Remove("a", 2).Remove("b", 5).Remove("x", 1)
+	assert.EqualInt(t, 2, b2.Len(), "b2.Len()")
+	assert.EqualInt(t, 2, b2.Count("a"), "b2.Count(a)")
+	assert.Equal(t, false, b2.Has("b"), "b2.Has(b)")
+	assert.EqualString(t, "{a:2}", b2.String(), "b2.String()")
+
+	x := b0.Add("a", 2).Add("b", 5)
+	y := b0.Add("a", 4).Add("c", 1)
+
+	tests := []struct {
+		name    string
+		bag     Bag[string]
+		a, b, c int
+	}{
+		{"Union", x.Union(y), 4, 5, 1},
+		{"Sum", x.Sum(y), 6, 5, 1},
+		{"Intersection", x.Intersection(y), 2, 0, 0},
+		{"Difference", x.Difference(y), 0, 5, 0},
+	}
+	for _, test := range tests {
+		assert.EqualInt(t, test.a, test.bag.Count("a"), "%s Count(a)", test.name)
+		assert.EqualInt(t, test.b, test.bag.Count("b"), "%s Count(b)", test.name)
+		assert.EqualInt(t, test.c, test.bag.Count("c"), "%s Count(c)", test.name)
+		assert.EqualInt(t, test.a+test.b+test.c, test.bag.Len(), "%s Len()", test.name)
+	}
+}
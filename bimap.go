@@ -0,0 +1,81 @@
+package immutable
+
+// BiMap is a persistent immutable one-to-one map that can be looked up by key
+// as well as by value. It keeps a forward and a reverse Map in lockstep, so
+// every key maps to exactly one value and every value to exactly one key.
+type BiMap[K, V comparable] struct {
+	forward Map[K, V]
+	reverse Map[V, K]
+}
+
+// Len returns the number of key,value pairs that are present.
+func (a BiMap[K, V]) Len() int {
+	return a.forward.Len()
+}
+
+// LookupKey returns the value associated with the given key along with the
+// value true when the key is present. Otherwise it returns (zero, false).
+func (a BiMap[K, V]) LookupKey(key K) (V, bool) {
+	return a.forward.Lookup(key)
+}
+
+// LookupValue returns the key associated with the given value along with the
+// value true when the value is present. Otherwise it returns (zero, false).
+func (a BiMap[K, V]) LookupValue(value V) (K, bool) {
+	return a.reverse.Lookup(value)
+}
+
+// HasKey returns true when the given key is present.
+func (a BiMap[K, V]) HasKey(key K) bool {
+	return a.forward.Has(key)
+}
+
+// HasValue returns true when the given value is present.
+func (a BiMap[K, V]) HasValue(value V) bool {
+	return a.reverse.Has(value)
+}
+
+// Range calls the given function for every key,value pair present.
+func (a BiMap[K, V]) Range(f func(K, V) bool) {
+	a.forward.Range(f)
+}
+
+// String returns a string representation of the key,value pairs present.
+func (a BiMap[K, V]) String() string {
+	return a.forward.String()
+}
+
+// Set returns a copy of the BiMap with the key associated with the value. Any
+// existing pair that uses either the key or the value is replaced.
+func (a BiMap[K, V]) Set(key K, value V) BiMap[K, V] {
+	if v, ok := a.forward.Lookup(key); ok {
+		a.reverse = a.reverse.Del(v)
+	}
+	if k, ok := a.reverse.Lookup(value); ok {
+		a.forward = a.forward.Del(k)
+	}
+	return BiMap[K, V]{a.forward.Set(key, value), a.reverse.Set(value, key)}
+}
+
+// DelKey returns a copy of the BiMap with the pair for the key removed.
+func (a BiMap[K, V]) DelKey(key K) BiMap[K, V] {
+	v, ok := a.forward.Lookup(key)
+	if !ok {
+		return a
+	}
+	return BiMap[K, V]{a.forward.Del(key), a.reverse.Del(v)}
+}
+
+// DelValue returns a copy of the BiMap with the pair for the value removed.
+func (a BiMap[K, V]) DelValue(value V) BiMap[K, V] {
+	k, ok := a.reverse.Lookup(value)
+	if !ok {
+		return a
+	}
+	return BiMap[K, V]{a.forward.Del(k), a.reverse.Del(value)}
+}
+
+// Inverse returns the BiMap with keys and values swapped in O(1) time.
+func (a BiMap[K, V]) Inverse() BiMap[V, K] {
+	return BiMap[V, K]{a.reverse, a.forward}
+}
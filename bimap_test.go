@@ -0,0 +1,35 @@
+package immutable
+
+import (
+	"testing"
+)
+
+func TestBiMap(t *testing.T) {
+	var m0 BiMap[int, string]
+	m1 := m0.Set(1, "one").Set(2, "two").Set(3, "three")
+
+	v, ok := m1.LookupKey(2)
+	assert.Equal(t, true, ok, "m1.LookupKey(2)")
+	assert.EqualString(t, "two", v, "m1.LookupKey(2)")
+	k, ok := m1.LookupValue("three")
+	assert.Equal(t, true, ok, "m1.LookupValue(three)")
+	assert.EqualInt(t, 3, k, "m1.LookupValue(three)")
+
+	// replacing the value of key 1 with the value of key 2 drops key 2
+	m2 := m1.Set(1, "two")
+	assert.EqualInt(t, 2, m2.Len(), "m2.Len()")
+	assert.Equal(t, false, m2.HasKey(2), "m2.HasKey(2)")
+	assert.Equal(t, false, m2.HasValue("one"), "m2.HasValue(one)")
+	k, _ = m2.LookupValue("two")
+	assert.EqualInt(t, 1, k, "m2.LookupValue(two)")
+	assert.EqualInt(t, 3, m1.Len(), "m1.Len()")
+
+	m3 := m1.DelKey(1).DelValue("three")
+	assert.EqualString(t, "{2:two}", m3.String(), "m3.String()")
+	assert.EqualString(t, "{two:2}", m3.Inverse().String(), "m3.Inverse().String()")
+
+	i := m1.Inverse().Set("four", 4)
+	v, _ = i.Inverse().LookupKey(4)
+	assert.EqualString(t, "four", v, "i.Inverse().LookupKey(4)")
+	assert.EqualInt(t, 4, i.Len(), "i.Len()")
+}
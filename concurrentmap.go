@@ -0,0 +1,140 @@
+package immutable
+
+import (
+	"sync/atomic"
+)
+
+// ConcurrentMap is a concurrency-safe mutable map that can be used instead of
+// sync.Map. It holds the root of an immutable Map in an atomic pointer. Reads
+// never block and work on the Map current at the time of the read. Writes
+// copy the path to the changed entry and install the new root with a
+// compare-and-swap, retrying when another write got in between. A snapshot
+// of the map is taken in O(1) time. The zero value is an empty map.
+type ConcurrentMap[K comparable, V any] struct {
+	root atomic.Pointer[Map[K, V]]
+}
+
+// Snapshot returns the current contents as an immutable Map in O(1) time.
+// Later writes to the ConcurrentMap do not affect the Map returned.
+func (c *ConcurrentMap[K, V]) Snapshot() Map[K, V] {
+	if m := c.root.Load(); m != nil {
+		return *m
+	}
+	return Map[K, V]{}
+}
+
+// update replaces the current Map with the Map returned by f. When f returns
+// false the current Map is left unchanged.
+func (c *ConcurrentMap[K, V]) update(f func(Map[K, V]) (Map[K, V], bool)) {
+	for {
+		p := c.root.Load()
+		var m Map[K, V]
+		if p != nil {
+			m = *p
+		}
+		next, ok := f(m)
+		if !ok || c.root.CompareAndSwap(p, &next) {
+			return
+		}
+	}
+}
+
+// Len returns the number of entries that are present.
+func (c *ConcurrentMap[K, V]) Len() int {
+	return c.Snapshot().Len()
+}
+
+// Load returns the value stored for the key along with the value true when
+// the key is present. Otherwise it returns (zero, false).
+func (c *ConcurrentMap[K, V]) Load(key K) (V, bool) {
+	return c.Snapshot().Lookup(key)
+}
+
+// Store sets the value for the key.
+func (c *ConcurrentMap[K, V]) Store(key K, value V) {
+	c.update(func(m Map[K, V]) (Map[K, V], bool) {
+		return m.Set(key, value), true
+	})
+}
+
+// LoadOrStore returns the existing value for the key along with the value
+// true when the key is present. Otherwise it stores the given value and
+// returns it along with the value false.
+func (c *ConcurrentMap[K, V]) LoadOrStore(key K, value V) (actual V, loaded bool) {
+	c.update(func(m Map[K, V]) (Map[K, V], bool) {
+		if actual, loaded = m.Lookup(key); loaded {
+			return m, false
+		}
+		actual = value
+		return m.Set(key, value), true
+	})
+	return actual, loaded
+}
+
+// LoadAndDelete deletes the value for the key and returns the value it had
+// along with the value true when the key was present.
+func (c *ConcurrentMap[K, V]) LoadAndDelete(key K) (value V, loaded bool) {
+	c.update(func(m Map[K, V]) (Map[K, V], bool) {
+		if value, loaded = m.Lookup(key); !loaded {
+			return m, false
+		}
+		return m.Del(key), true
+	})
+	return value, loaded
+}
+
+// Delete deletes the value for the key.
+func (c *ConcurrentMap[K, V]) Delete(key K) {
+	c.LoadAndDelete(key)
+}
+
+// Swap stores the value for the key and returns the previous value along
+// with the value true when the key was present.
+func (c *ConcurrentMap[K, V]) Swap(key K, value V) (previous V, loaded bool) {
+	c.update(func(m Map[K, V]) (Map[K, V], bool) {
+		previous, loaded = m.Lookup(key)
+		return m.Set(key, value), true
+	})
+	return previous, loaded
+}
+
+// CompareAndSwap stores the new value for the key when the key is present
+// and its value is equal to old, and returns whether it did. Like for
+// sync.Map, the values are compared with == and the old value must be of a
+// comparable type.
+func (c *ConcurrentMap[K, V]) CompareAndSwap(key K, old, new V) (swapped bool) {
+	c.update(func(m Map[K, V]) (Map[K, V], bool) {
+		value, ok := m.Lookup(key)
+		if swapped = ok && any(value) == any(old); !swapped {
+			return m, false
+		}
+		return m.Set(key, new), true
+	})
+	return swapped
+}
+
+// CompareAndDelete deletes the key when it is present and its value is equal
+// to old, and returns whether it did. Like for sync.Map, the values are
+// compared with == and the old value must be of a comparable type.
+func (c *ConcurrentMap[K, V]) CompareAndDelete(key K, old V) (deleted bool) {
+	c.update(func(m Map[K, V]) (Map[K, V], bool) {
+		value, ok := m.Lookup(key)
+		if deleted = ok && any(value) == any(old); !deleted {
+			return m, false
+		}
+		return m.Del(key), true
+	})
+	return deleted
+}
+
+// Clear deletes all entries. Snapshots taken before are not affected.
+func (c *ConcurrentMap[K, V]) Clear() {
+	c.root.Store(nil)
+}
+
+// Range calls the given function for every key,value pair present in a
+// snapshot taken when Range was called. Writes made while Range is running
+// are not seen by Range.
+func (c *ConcurrentMap[K, V]) Range(f func(K, V) bool) {
+	c.Snapshot().Range(f)
+}
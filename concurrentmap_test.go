@@ -0,0 +1,72 @@
+package immutable
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestConcurrentMap(t *testing.T) {
+	var c ConcurrentMap[int, int]
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				c.Store(i*100+j, j)
+				c.Load(j)
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.EqualInt(t, 800, c.Len(), "c.Len()")
+
+	s := c.Snapshot()
+	v, loaded := c.LoadOrStore(5, 50)
+	assert.Equal(t, true, loaded, "c.LoadOrStore(5, 50) loaded")
+	assert.EqualInt(t, 5, v, "c.LoadOrStore(5, 50)")
+	v, loaded = c.LoadOrStore(-1, 10)
+	assert.Equal(t, false, loaded, "c.LoadOrStore(-1, 10) loaded")
+	assert.EqualInt(t, 10, v, "c.LoadOrStore(-1, 10)")
+	v, loaded = c.Swap(-1, 20)
+	assert.Equal(t, true, loaded, "c.Swap(-1, 20) loaded")
+	assert.EqualInt(t, 10, v, "c.Swap(-1, 20)")
+	v, loaded = c.LoadAndDelete(-1)
+	assert.Equal(t, true, loaded, "c.LoadAndDelete(-1) loaded")
+	assert.EqualInt(t, 20, v, "c.LoadAndDelete(-1)")
+	_, loaded = c.LoadAndDelete(-1)
+	assert.Equal(t, false, loaded, "c.LoadAndDelete(-1) again")
+	c.Delete(0)
+	_, ok := c.Load(0)
+	assert.Equal(t, false, ok, "c.Load(0)")
+	assert.EqualInt(t, 800, s.Len(), "s.Len()")
+	assert.Equal(t, true, s.Has(0), "s.Has(0)")
+
+	n := 0
+	c.Range(func(k, v int) bool {
+		c.Delete(k)
+		n++
+		return true
+	})
+	assert.EqualInt(t, 799, n, "Range count")
+	assert.EqualInt(t, 0, c.Len(), "c.Len()")
+
+	c.Store(1, 10)
+	assert.Equal(t, false, c.CompareAndSwap(1, 20, 30), "c.CompareAndSwap(1, 20, 30)")
+	assert.Equal(t, false, c.CompareAndSwap(2, 0, 30), "c.CompareAndSwap(2, 0, 30)")
+	assert.Equal(t, true, c.CompareAndSwap(1, 10, 30), "c.CompareAndSwap(1, 10, 30)")
+	v, _ = c.Load(1)
+	assert.EqualInt(t, 30, v, "c.Load(1)")
+	assert.Equal(t, false, c.CompareAndDelete(1, 10), "c.CompareAndDelete(1, 10)")
+	assert.Equal(t, true, c.CompareAndDelete(1, 30), "c.CompareAndDelete(1, 30)")
+	assert.EqualInt(t, 0, c.Len(), "c.Len()")
+
+	c.Store(1, 10)
+	c.Store(2, 20)
+	s = c.Snapshot()
+	c.Clear()
+	assert.EqualInt(t, 0, c.Len(), "c.Clear() c.Len()")
+	assert.EqualInt(t, 2, s.Len(), "c.Clear() s.Len()")
+	c.Store(3, 30)
+	assert.EqualInt(t, 1, c.Len(), "c.Len() after Clear")
+}
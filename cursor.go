@@ -0,0 +1,188 @@
+package immutable
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"hash/maphash"
+	"math/bits"
+)
+
+// fingerprint identifies the seed of the hash function of the process. A
+// cursor for keys hashed with the seed is only meaningful for maps built
+// with the same seed.
+var fingerprint = uint32(maphash.String(seed, "immutable.Cursor"))
+
+// seeded returns true when the hash of keys of type K depends on the random
+// seed of the process. Integer keys are hashed without the seed, so their
+// order is the same in every process.
+func seeded[K any]() bool {
+	switch any(*new(K)).(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return false
+	}
+	return true
+}
+
+// Cursor is a position in the trie order of a Map, Set or Store. The trie
+// order is the order in which Range visits entries. It sorts entries on the
+// 5 bit groups of their hash prefix, lowest group first. Entries that share
+// the same prefix are ordered by their index in the collision level. A
+// cursor holds the prefix and the collision index of the next entry to
+// visit, so it stays valid when the map is modified. The zero value of a
+// Cursor is positioned before the first entry.
+//
+// For string keys the hash prefix depends on the random hash seed of the
+// process, so such a cursor can only be resumed by the process that created
+// it. After a restart or on another process its token is rejected by
+// ParseCursor. A cursor for integer keys does not depend on the seed and can
+// be resumed by any process.
+type Cursor struct {
+	prefix uint32
+	index  int
+	done   bool
+	seeded bool
+}
+
+// Done returns true when the cursor is positioned after the last entry.
+func (c Cursor) Done() bool {
+	return c.done
+}
+
+// String returns the cursor serialized as an opaque token that can be
+// passed to ParseCursor. When the order of the entries depends on the hash
+// seed of the process, the token contains a fingerprint of the seed.
+func (c Cursor) String() string {
+	b := make([]byte, 9, 9+binary.MaxVarintLen64)
+	if c.done {
+		b[0] |= 1
+	}
+	if c.seeded {
+		b[0] |= 2
+		binary.LittleEndian.PutUint32(b[1:], fingerprint)
+	}
+	binary.LittleEndian.PutUint32(b[5:], c.prefix)
+	b = binary.AppendUvarint(b, uint64(c.index))
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// ParseCursor returns the cursor serialized in the token. It returns the
+// error InvalidCursor when the token is malformed or contains the fingerprint
+// of a different hash seed.
+func ParseCursor(token string) (Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(b) < 10 || b[0] > 3 {
+		return Cursor{}, InvalidCursor
+	}
+	seeded := b[0]&2 != 0
+	if f := binary.LittleEndian.Uint32(b[1:]); seeded && f != fingerprint || !seeded && f != 0 {
+		return Cursor{}, InvalidCursor
+	}
+	index, n := binary.Uvarint(b[9:])
+	if n <= 0 || 9+n != len(b) || index > uint64(^uint(0)>>1) {
+		return Cursor{}, InvalidCursor
+	}
+	return Cursor{binary.LittleEndian.Uint32(b[5:]), int(index), b[0]&1 != 0, seeded}, nil
+}
+
+// order returns the prefix with its 5 bit groups reversed, so comparing the
+// results of two prefixes compares them in trie order.
+func order(prefix uint32) uint64 {
+	var o uint64
+	for shift := uint8(0); shift < collision; shift += nextlevel {
+		o = o<<nextlevel | uint64(prefix>>shift&0x1f)
+	}
+	return o
+}
+
+// before returns true when the entry with the given prefix and collision
+// index comes before the position of the cursor.
+func (c Cursor) before(prefix uint32, index int) bool {
+	if o, oc := order(prefix), order(c.prefix); o != oc {
+		return o < oc
+	}
+	return index < c.index
+}
+
+// scan calls f in trie order with every entry holding a key,value pair in n
+// that is at or after the cursor, along with its collision index. While
+// bound is true, n lies on the path to the cursor and entries before the
+// cursor are skipped.
+func (n amt[K, V]) scan(shift uint8, c Cursor, bound bool, f func(*entry[V], int) bool) bool {
+	if shift == collision {
+		for i, e := range n.entries {
+			if (!bound || !c.before(e.prefix, i)) && !f(e, i) {
+				return false
+			}
+		}
+		return true
+	}
+	group := int(c.prefix >> shift & 0x1f)
+	bitmap := n.bits
+	for _, e := range n.entries {
+		slot := bits.TrailingZeros32(bitmap)
+		bitmap &= bitmap - 1
+		if bound && slot < group {
+			continue
+		}
+		if a, ok := e.ref.(amt[K, V]); ok {
+			if !a.scan(shift+nextlevel, c, bound && slot == group, f) {
+				return false
+			}
+		} else if (!bound || !c.before(e.prefix, 0)) && !f(e, 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// advance calls f for at most count entries starting at the cursor and
+// returns the cursor positioned at the entry after the last entry passed to
+// f. It stops early when f returns false.
+func (n amt[K, V]) advance(c Cursor, count int, f func(K, V) bool) Cursor {
+	if c.done {
+		return c
+	}
+	next := Cursor{done: true, seeded: seeded[K]()}
+	stop := false
+	n.scan(0, c, true, func(e *entry[V], index int) bool {
+		if stop || count <= 0 {
+			next = Cursor{prefix: e.prefix, index: index, seeded: next.seeded}
+			return false
+		}
+		count--
+		stop = !f(refKey[K](e.ref), e.value)
+		return true
+	})
+	return next
+}
+
+// Scan calls the given function for at most n key,value pairs starting at
+// the position of the cursor and returns a cursor positioned after the last
+// pair passed to f. Scan stops early when f returns false. A Map can be
+// scanned page by page by passing the cursor returned by one call to the
+// next call until the cursor is done. The Map may be modified between calls.
+// Entries present during the whole scan are visited exactly once. Entries
+// inserted between calls are visited when they come after the cursor in
+// trie order, entries deleted between calls are not visited when they come
+// after the cursor. Entries that share a hash prefix with other entries are
+// visited based on their collision index, so inserting or deleting such
+// entries may cause others with the same prefix to be skipped or repeated.
+// For keys other than integers the cursor can only be resumed by the same
+// process, see Cursor.
+func (a Map[K, V]) Scan(c Cursor, n int, f func(K, V) bool) Cursor {
+	return a.advance(c, n, f)
+}
+
+// Scan calls the given function for at most n keys starting at the position
+// of the cursor and returns a cursor positioned after the last key passed to
+// f. It works in the same way as Map.Scan.
+func (a Set[K]) Scan(c Cursor, n int, f func(K) bool) Cursor {
+	return a.advance(c, n, func(key K, _ struct{}) bool { return f(key) })
+}
+
+// Scan calls the given function for at most n key,value pairs starting at
+// the position of the cursor and returns a cursor positioned after the last
+// pair passed to f. It works in the same way as Map.Scan.
+func (a Store[D, K, V]) Scan(c Cursor, n int, f func(K, V) bool) Cursor {
+	return a.advance(c, n, f)
+}
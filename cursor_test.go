@@ -0,0 +1,131 @@
+package immutable
+
+import (
+	"testing"
+)
+
+func TestScan(t *testing.T) {
+	m, ranged := collidingMap()
+
+	var scanned []int64
+	var c Cursor
+	for pages := 0; !c.Done(); pages++ {
+		token := c.String()
+		parsed, err := ParseCursor(token)
+		assert.Equal(t, nil, err, "ParseCursor(%q)", token)
+		assert.Equal(t, c, parsed, "ParseCursor(%q)", token)
+		c = m.Scan(parsed, 100, func(k int64, _ int) bool {
+			scanned = append(scanned, k)
+			return true
+		})
+		assert.Equal(t, true, pages <= len(ranged)/100, "page %d", pages)
+	}
+	assert.EqualInt(t, len(ranged), len(scanned), "len(scanned)")
+	for i := range ranged {
+		if ranged[i] != scanned[i] {
+			t.Fatalf("scanned[%d] expected %d got %d", i, ranged[i], scanned[i])
+		}
+	}
+
+	done := m.Scan(c, 10, func(int64, int) bool {
+		t.Error("called on done cursor")
+		return true
+	})
+	assert.Equal(t, true, done.Done(), "done.Done()")
+}
+
+func TestScanModified(t *testing.T) {
+	var m Map[string, int]
+	for i := 0; i < 1000; i++ {
+		m = m.Set(letterKey(i), i)
+	}
+	seen := map[string]int{}
+	var c Cursor
+	for i := 0; !c.Done(); i++ {
+		var last string
+		c = m.Scan(c, 50, func(k string, _ int) bool {
+			seen[k]++
+			last = k
+			return true
+		})
+		// delete an entry that was seen and insert a new one
+		m = m.Del(last).Set("new"+string(rune('A'+i)), -1)
+	}
+	for i := 0; i < 1000; i++ {
+		k := letterKey(i)
+		assert.EqualInt(t, 1, seen[k], "seen[%s]", k)
+	}
+}
+
+func TestScanStop(t *testing.T) {
+	s := Set[int]{}.Put(1).Put(2).Put(3)
+	var keys []int
+	c := s.Scan(Cursor{}, 10, func(k int) bool {
+		keys = append(keys, k)
+		return false
+	})
+	assert.Equal(t, false, c.Done(), "c.Done()")
+	c = s.Scan(c, 10, func(k int) bool {
+		keys = append(keys, k)
+		return true
+	})
+	assert.Equal(t, true, c.Done(), "c.Done()")
+	assert.EqualInt(t, 3, len(keys), "len(keys)")
+	assert.EqualInt(t, 1, keys[0], "keys[0]")
+}
+
+func TestParseCursorInvalid(t *testing.T) {
+	for _, token := range []string{"", "!", "AAAA", Cursor{}.String() + "A"} {
+		_, err := ParseCursor(token)
+		assert.Equal(t, InvalidCursor, err, "ParseCursor(%q)", token)
+	}
+}
+
+func TestCursorSeed(t *testing.T) {
+	var ints Map[int, int]
+	var strs Map[string, int]
+	for i := 0; i < 100; i++ {
+		ints = ints.Set(i, i)
+		strs = strs.Set(letterKey(i), i)
+	}
+	ci := ints.Scan(Cursor{}, 10, func(int, int) bool { return true })
+	cs := strs.Scan(Cursor{}, 10, func(string, int) bool { return true })
+	assert.Equal(t, false, ci.seeded, "ci.seeded")
+	assert.Equal(t, true, cs.seeded, "cs.seeded")
+
+	// parse the tokens in a process with a different hash seed
+	ti, ts := ci.String(), cs.String()
+	saved := fingerprint
+	fingerprint++
+	defer func() { fingerprint = saved }()
+	parsed, err := ParseCursor(ti)
+	assert.Equal(t, nil, err, "ParseCursor(ints token)")
+	assert.Equal(t, ci, parsed, "ParseCursor(ints token)")
+	_, err = ParseCursor(ts)
+	assert.Equal(t, InvalidCursor, err, "ParseCursor(strs token)")
+}
+
+// collidingMap returns a Map with 3000 int64 keys along with the keys in the
+// order visited by Range. Every seventh key has the same hash as another key,
+// so the Map has collision levels.
+func collidingMap() (Map[int64, int], []int64) {
+	var m Map[int64, int]
+	for i := 0; i < 3000; i++ {
+		k := int64(i * 31)
+		if i%7 == 0 {
+			k = int64(i%100*31) + 1<<32
+		}
+		m = m.Set(k, i)
+	}
+	var ranged []int64
+	m.Range(func(k int64, _ int) bool {
+		ranged = append(ranged, k)
+		return true
+	})
+	return m, ranged
+}
+
+// letterKey returns a distinct two letter string key for every i.
+func letterKey(i int) string {
+	return string(rune('a'+i%26)) + string(rune('A'+i/26))
+}
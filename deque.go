@@ -0,0 +1,81 @@
+package immutable
+
+// Deque is a persistent immutable double-ended queue. It is built on Vector,
+// so versions share structure and PushBack, PopBack take amortized O(1)
+// time while PushFront, PopFront, Get and Concat take O(log n) time.
+type Deque[T any] struct{ vector Vector[T] }
+
+// Len returns the number of values in the deque.
+func (a Deque[T]) Len() int {
+	return a.vector.Len()
+}
+
+// Get returns the value at the given index counting from the front. It
+// panics with IndexOutOfRange when the index is not in the range [0, Len()).
+func (a Deque[T]) Get(index int) T {
+	return a.vector.Get(index)
+}
+
+// Front returns the value at the front along with the value true. It
+// returns (zero, false) when the deque is empty.
+func (a Deque[T]) Front() (T, bool) {
+	if a.vector.Len() == 0 {
+		var zero T
+		return zero, false
+	}
+	return a.vector.Get(0), true
+}
+
+// Back returns the value at the back along with the value true. It returns
+// (zero, false) when the deque is empty.
+func (a Deque[T]) Back() (T, bool) {
+	if a.vector.Len() == 0 {
+		var zero T
+		return zero, false
+	}
+	return a.vector.Get(a.vector.Len() - 1), true
+}
+
+// PushFront returns a copy of the deque with the value added at the front.
+func (a Deque[T]) PushFront(value T) Deque[T] {
+	return Deque[T]{Vector[T]{}.Append(value).Concat(a.vector)}
+}
+
+// PushBack returns a copy of the deque with the value added at the back.
+func (a Deque[T]) PushBack(value T) Deque[T] {
+	return Deque[T]{a.vector.Append(value)}
+}
+
+// PopFront returns the value at the front and the deque without that value
+// along with the value true. It returns (zero, a, false) when the deque is
+// empty.
+func (a Deque[T]) PopFront() (T, Deque[T], bool) {
+	v, ok := a.Front()
+	if !ok {
+		return v, a, false
+	}
+	return v, Deque[T]{a.vector.Slice(1, a.vector.Len())}, true
+}
+
+// PopBack returns the value at the back and the deque without that value
+// along with the value true. It returns (zero, a, false) when the deque is
+// empty.
+func (a Deque[T]) PopBack() (T, Deque[T], bool) {
+	v, vector, ok := a.vector.Pop()
+	return v, Deque[T]{vector}, ok
+}
+
+// Concat returns a deque with the values of a followed by the values of b.
+func (a Deque[T]) Concat(b Deque[T]) Deque[T] {
+	return Deque[T]{a.vector.Concat(b.vector)}
+}
+
+// Range calls the given function for every value from front to back.
+func (a Deque[T]) Range(f func(T) bool) {
+	a.vector.Range(func(_ int, v T) bool { return f(v) })
+}
+
+// String returns a string representation of the values from front to back.
+func (a Deque[T]) String() string {
+	return a.vector.String()
+}
@@ -0,0 +1,51 @@
+package immutable
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestDeque(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	var d Deque[int]
+	var ref []int
+	for i := 0; i < 5000; i++ {
+		switch rnd.Intn(5) {
+		case 0:
+			d, ref = d.PushFront(i), append([]int{i}, ref...)
+		case 1:
+			d, ref = d.PushBack(i), append(ref, i)
+		case 2:
+			v, e, ok := d.PopFront()
+			assert.Equal(t, len(ref) > 0, ok, "d.PopFront() #%d", i)
+			if ok {
+				assert.EqualInt(t, ref[0], v, "d.PopFront() #%d", i)
+				d, ref = e, ref[1:]
+			}
+		case 3:
+			v, e, ok := d.PopBack()
+			assert.Equal(t, len(ref) > 0, ok, "d.PopBack() #%d", i)
+			if ok {
+				assert.EqualInt(t, ref[len(ref)-1], v, "d.PopBack() #%d", i)
+				d, ref = e, ref[:len(ref)-1]
+			}
+		case 4:
+			d, ref = d.PushBack(i).PushFront(-i), append(append([]int{-i}, ref...), i)
+		}
+	}
+	assert.EqualInt(t, len(ref), d.Len(), "d.Len()")
+	for i, v := range ref {
+		assert.EqualInt(t, v, d.Get(i), "d.Get(%d)", i)
+	}
+	if len(ref) > 0 {
+		front, _ := d.Front()
+		back, _ := d.Back()
+		assert.EqualInt(t, ref[0], front, "d.Front()")
+		assert.EqualInt(t, ref[len(ref)-1], back, "d.Back()")
+	}
+
+	e := Deque[int]{}.PushBack(1).PushBack(2).Concat(Deque[int]{}.PushFront(4).PushFront(3))
+	assert.EqualString(t, "[1, 2, 3, 4]", e.String(), "e.String()")
+	_, _, ok := Deque[int]{}.PopFront()
+	assert.Equal(t, false, ok, "Deque{}.PopFront()")
+}
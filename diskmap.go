@@ -0,0 +1,423 @@
+package immutable
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/fnv"
+	"math/bits"
+)
+
+// DiskMap is a persistent immutable hash array mapped trie (HAMT) whose nodes
+// live in a NodeStore. Nodes are loaded on demand while walking the trie and
+// a modification appends only the nodes on the path from the root to the
+// modified entry. Every version of the map is identified by the offset of its
+// root node, so older versions remain readable for as long as the store keeps
+// them.
+//
+// Keys and values are converted to bytes with the marshal function and back
+// with the unmarshal function, e.g. json.Marshal and json.Unmarshal. Keys are
+// compared by their marshaled representation and hashed with a stable hash
+// function, so a DiskMap can be reopened by a different process.
+type DiskMap[K, V any] struct {
+	store     NodeStore
+	root      int64
+	marshal   func(any) ([]byte, error)
+	unmarshal func([]byte, any) error
+}
+
+func DiskMapWith[K, V any](store NodeStore, root int64, marshal func(any) ([]byte, error), unmarshal func([]byte, any) error) DiskMap[K, V] {
+	return DiskMap[K, V]{store, root, marshal, unmarshal}
+}
+
+// dnode is the decoded form of a trie node read from the NodeStore.
+type dnode struct {
+	bits    uint32
+	entries []dentry
+}
+
+// dentry either refers to a child node by its offset or holds a marshaled
+// key,value pair.
+type dentry struct {
+	prefix uint32
+	offset int64
+	key    []byte
+	value  []byte
+}
+
+func stablehash(key []byte) uint32 {
+	h := fnv.New32a()
+	h.Write(key)
+	return h.Sum32()
+}
+
+func (n dnode) encode() []byte {
+	buf := binary.LittleEndian.AppendUint32(nil, n.bits)
+	buf = binary.AppendUvarint(buf, uint64(len(n.entries)))
+	for _, e := range n.entries {
+		if e.offset != 0 {
+			buf = append(buf, 1)
+			buf = binary.AppendUvarint(buf, uint64(e.offset))
+		} else {
+			buf = append(buf, 0)
+			buf = binary.LittleEndian.AppendUint32(buf, e.prefix)
+			buf = binary.AppendUvarint(buf, uint64(len(e.key)))
+			buf = append(buf, e.key...)
+			buf = binary.AppendUvarint(buf, uint64(len(e.value)))
+			buf = append(buf, e.value...)
+		}
+	}
+	return buf
+}
+
+func decode(offset int64, data []byte) (dnode, error) {
+	var n dnode
+	if len(data) < 4 {
+		return n, InvalidNodeOffset
+	}
+	n.bits = binary.LittleEndian.Uint32(data)
+	data = data[4:]
+	count, k := binary.Uvarint(data)
+	if k <= 0 {
+		return n, InvalidNodeOffset
+	}
+	// the bitmap has a bit for every entry, except in the collision level
+	if n.bits != 0 && uint64(bits.OnesCount32(n.bits)) != count {
+		return n, InvalidNodeOffset
+	}
+	data = data[k:]
+	field := func() []byte {
+		size, k := binary.Uvarint(data)
+		if k <= 0 || uint64(len(data)-k) < size {
+			return nil
+		}
+		b := data[k : k+int(size)]
+		data = data[k+int(size):]
+		return b
+	}
+	// every entry takes at least one byte, only the collision level holds
+	// more than branching entries
+	if count > uint64(len(data)) {
+		return n, InvalidNodeOffset
+	}
+	if count > branching {
+		n.entries = make([]dentry, 0, branching)
+	} else {
+		n.entries = make([]dentry, 0, count)
+	}
+	for i := uint64(0); i < count; i++ {
+		if len(data) == 0 {
+			return n, InvalidNodeOffset
+		}
+		kind := data[0]
+		data = data[1:]
+		if kind == 1 {
+			child, k := binary.Uvarint(data)
+			// children are always appended before their parent
+			if k <= 0 || child == 0 || child >= uint64(offset) {
+				return n, InvalidNodeOffset
+			}
+			data = data[k:]
+			n.entries = append(n.entries, dentry{offset: int64(child)})
+		} else {
+			if len(data) < 4 {
+				return n, InvalidNodeOffset
+			}
+			e := dentry{prefix: binary.LittleEndian.Uint32(data)}
+			data = data[4:]
+			if e.key = field(); e.key == nil {
+				return n, InvalidNodeOffset
+			}
+			if e.value = field(); e.value == nil {
+				return n, InvalidNodeOffset
+			}
+			n.entries = append(n.entries, e)
+		}
+	}
+	return n, nil
+}
+
+func (a DiskMap[K, V]) load(offset int64) (dnode, error) {
+	if offset == 0 {
+		return dnode{}, nil
+	}
+	data, err := a.store.Read(offset)
+	if err != nil {
+		return dnode{}, err
+	}
+	return decode(offset, data)
+}
+
+func (a DiskMap[K, V]) save(n dnode) (int64, error) {
+	return a.store.Append(n.encode())
+}
+
+func (a DiskMap[K, V]) lookup(prefix uint32, key []byte) ([]byte, bool, error) {
+	offset := a.root
+	for shift := uint8(0); offset != 0; shift += nextlevel {
+		n, err := a.load(offset)
+		if err != nil {
+			return nil, false, err
+		}
+		bitpos := bitpos(prefix, shift)
+		if present(n.bits, bitpos) {
+			e := n.entries[index(n.bits, bitpos)]
+			if e.offset != 0 {
+				offset = e.offset
+				continue
+			}
+			if e.prefix == prefix && bytes.Equal(e.key, key) {
+				return e.value, true, nil
+			}
+		} else if shift == collision {
+			for _, e := range n.entries {
+				if e.prefix == prefix && bytes.Equal(e.key, key) {
+					return e.value, true, nil
+				}
+			}
+		}
+		break
+	}
+	return nil, false, nil
+}
+
+// pair returns the offset of a newly stored node holding the 2 entries.
+func (a DiskMap[K, V]) pair(shift uint8, e1, e2 dentry) (int64, error) {
+	var n dnode
+	b1, b2 := bitpos(e1.prefix, shift), bitpos(e2.prefix, shift)
+	switch {
+	case shift == collision:
+		n.entries = []dentry{e1, e2}
+	case b1 == b2:
+		offset, err := a.pair(shift+nextlevel, e1, e2)
+		if err != nil {
+			return 0, err
+		}
+		n.bits = b1
+		n.entries = []dentry{{offset: offset}}
+	case b1 < b2:
+		n.bits = b1 | b2
+		n.entries = []dentry{e1, e2}
+	default:
+		n.bits = b1 | b2
+		n.entries = []dentry{e2, e1}
+	}
+	return a.save(n)
+}
+
+func (a DiskMap[K, V]) set(offset int64, shift uint8, item dentry) (int64, error) {
+	n, err := a.load(offset)
+	if err != nil {
+		return 0, err
+	}
+	bitpos := bitpos(item.prefix, shift)
+	if present(n.bits, bitpos) {
+		index := index(n.bits, bitpos)
+		e := n.entries[index]
+		if e.offset != 0 {
+			if offset, err = a.set(e.offset, shift+nextlevel, item); err != nil {
+				return 0, err
+			}
+			n.entries[index] = dentry{offset: offset}
+		} else if e.prefix == item.prefix && bytes.Equal(e.key, item.key) {
+			n.entries[index] = item
+		} else {
+			// replace item with a new node holding the 2 items
+			if offset, err = a.pair(shift+nextlevel, e, item); err != nil {
+				return 0, err
+			}
+			n.entries[index] = dentry{offset: offset}
+		}
+	} else if shift < collision {
+		index := index(n.bits, bitpos)
+		n.bits |= bitpos
+		n.entries = append(n.entries, dentry{})
+		copy(n.entries[index+1:], n.entries[index:])
+		n.entries[index] = item
+	} else {
+		replaced := false
+		for index, e := range n.entries {
+			if bytes.Equal(e.key, item.key) {
+				n.entries[index] = item
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			n.entries = append(n.entries, item)
+		}
+	}
+	return a.save(n)
+}
+
+// delete returns the modified node without storing it, so the caller can
+// decide to pull up a single remaining entry. The node is nil when the key
+// was not present.
+func (a DiskMap[K, V]) delete(offset int64, prefix uint32, shift uint8, key []byte) (*dnode, error) {
+	n, err := a.load(offset)
+	if err != nil {
+		return nil, err
+	}
+	bitpos := bitpos(prefix, shift)
+	if present(n.bits, bitpos) {
+		index := index(n.bits, bitpos)
+		e := n.entries[index]
+		if e.offset != 0 {
+			c, err := a.delete(e.offset, prefix, shift+nextlevel, key)
+			if c == nil || err != nil {
+				return nil, err
+			}
+			if len(c.entries) == 1 && c.entries[0].offset == 0 {
+				n.entries[index] = c.entries[0]
+			} else {
+				if offset, err = a.save(*c); err != nil {
+					return nil, err
+				}
+				n.entries[index] = dentry{offset: offset}
+			}
+			return &n, nil
+		}
+		if e.prefix == prefix && bytes.Equal(e.key, key) {
+			n.entries = append(n.entries[:index], n.entries[index+1:]...)
+			n.bits &= ^bitpos
+			return &n, nil
+		}
+	} else if shift == collision {
+		for index, e := range n.entries {
+			if e.prefix == prefix && bytes.Equal(e.key, key) {
+				n.entries = append(n.entries[:index], n.entries[index+1:]...)
+				return &n, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (a DiskMap[K, V]) foreach(offset int64, f func(e dentry) (bool, error)) (bool, error) {
+	n, err := a.load(offset)
+	if err != nil {
+		return false, err
+	}
+	for _, e := range n.entries {
+		var more bool
+		if e.offset != 0 {
+			more, err = a.foreach(e.offset, f)
+		} else {
+			more, err = f(e)
+		}
+		if !more || err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// Root returns the offset of the root node that identifies this version of
+// the map. The empty map has root offset 0.
+func (a DiskMap[K, V]) Root() int64 {
+	return a.root
+}
+
+// At returns the version of the map with the given root offset.
+func (a DiskMap[K, V]) At(root int64) DiskMap[K, V] {
+	return DiskMap[K, V]{a.store, root, a.marshal, a.unmarshal}
+}
+
+// Len returns the number of entries that are present. Len has to load every
+// node of the map from the store.
+func (a DiskMap[K, V]) Len() (int, error) {
+	len := 0
+	_, err := a.foreach(a.root, func(dentry) (bool, error) {
+		len++
+		return true, nil
+	})
+	return len, err
+}
+
+// Lookup returns the value of an entry associated with a given key along with
+// the value true when the key is present. Otherwise it returns (zero, false).
+func (a DiskMap[K, V]) Lookup(key K) (V, bool, error) {
+	var value V
+	k, err := a.marshal(key)
+	if err != nil {
+		return value, false, err
+	}
+	v, ok, err := a.lookup(stablehash(k), k)
+	if !ok || err != nil {
+		return value, false, err
+	}
+	if err := a.unmarshal(v, &value); err != nil {
+		return value, false, err
+	}
+	return value, true, nil
+}
+
+// Has returns true when an entry with the given key is present.
+func (a DiskMap[K, V]) Has(key K) (bool, error) {
+	k, err := a.marshal(key)
+	if err != nil {
+		return false, err
+	}
+	_, ok, err := a.lookup(stablehash(k), k)
+	return ok, err
+}
+
+// Get returns the value for the entry with the given key or zero value
+// when it is not present.
+func (a DiskMap[K, V]) Get(key K) (V, error) {
+	v, _, err := a.Lookup(key)
+	return v, err
+}
+
+// Range calls the given function for every key,value pair present.
+func (a DiskMap[K, V]) Range(f func(K, V) bool) error {
+	_, err := a.foreach(a.root, func(e dentry) (bool, error) {
+		var key K
+		var value V
+		if err := a.unmarshal(e.key, &key); err != nil {
+			return false, err
+		}
+		if err := a.unmarshal(e.value, &value); err != nil {
+			return false, err
+		}
+		return f(key, value), nil
+	})
+	return err
+}
+
+// Set returns a copy of the map with the given key,value pair inserted. Only
+// the nodes on the path to the entry are appended to the store.
+func (a DiskMap[K, V]) Set(key K, value V) (DiskMap[K, V], error) {
+	k, err := a.marshal(key)
+	if err != nil {
+		return a, err
+	}
+	v, err := a.marshal(value)
+	if err != nil {
+		return a, err
+	}
+	root, err := a.set(a.root, 0, dentry{prefix: stablehash(k), key: k, value: v})
+	if err != nil {
+		return a, err
+	}
+	return a.At(root), nil
+}
+
+// Del returns a copy of the map with the entry for the key removed.
+func (a DiskMap[K, V]) Del(key K) (DiskMap[K, V], error) {
+	k, err := a.marshal(key)
+	if err != nil {
+		return a, err
+	}
+	n, err := a.delete(a.root, stablehash(k), 0, k)
+	if n == nil || err != nil {
+		return a, err
+	}
+	if len(n.entries) == 0 {
+		return a.At(0), nil
+	}
+	root, err := a.save(*n)
+	if err != nil {
+		return a, err
+	}
+	return a.At(root), nil
+}
@@ -0,0 +1,161 @@
+package immutable
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiskMap(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "nodes")
+	store, err := OpenFileNodeStore(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m0 := DiskMapWith[string, int](store, 0, json.Marshal, json.Unmarshal)
+	m1 := m0
+	for i := 0; i < 100; i++ {
+		if m1, err = m1.Set(fmt.Sprint("key", i), i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m2, err := m1.Set("key42", 4242)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m3, err := m2.Del("key7")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	len, err := m1.Len()
+	assert.Equal(t, nil, err, "m1.Len()")
+	assert.EqualInt(t, 100, len, "m1.Len()")
+	len, _ = m3.Len()
+	assert.EqualInt(t, 99, len, "m3.Len()")
+
+	v, ok, err := m1.Lookup("key42")
+	assert.Equal(t, nil, err, "m1.Lookup(key42)")
+	assert.Equal(t, true, ok, "m1.Lookup(key42)")
+	assert.EqualInt(t, 42, v, "m1.Lookup(key42)")
+	v, _ = m2.Get("key42")
+	assert.EqualInt(t, 4242, v, "m2.Get(key42)")
+	ok, _ = m2.Has("key7")
+	assert.Equal(t, true, ok, "m2.Has(key7)")
+	ok, _ = m3.Has("key7")
+	assert.Equal(t, false, ok, "m3.Has(key7)")
+	_, ok, _ = m0.Lookup("key42")
+	assert.Equal(t, false, ok, "m0.Lookup(key42)")
+
+	root := m3.Root()
+	store.Close()
+
+	store, err = OpenFileNodeStore(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	m3 = DiskMapWith[string, int](store, root, json.Marshal, json.Unmarshal)
+	sum := 0
+	err = m3.Range(func(key string, value int) bool {
+		sum += value
+		return true
+	})
+	assert.Equal(t, nil, err, "m3.Range()")
+	assert.EqualInt(t, 4950-7-42+4242, sum, "m3.Range()")
+
+	m4 := m3
+	for i := 0; i < 100; i++ {
+		if m4, err = m4.Del(fmt.Sprint("key", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assert.Equal(t, int64(0), m4.Root(), "m4.Root()")
+	v, _ = m3.At(m1.Root()).Get("key7")
+	assert.EqualInt(t, 7, v, "m3.At(m1.Root()).Get(key7)")
+}
+
+func TestDiskMapCollision(t *testing.T) {
+	store, err := OpenFileNodeStore(filepath.Join(t.TempDir(), "nodes"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	// e1 and e2 have the same prefix and end up in the collision level, e3
+	// shares the first 2 levels with them.
+	m := DiskMapWith[string, string](store, 0, json.Marshal, json.Unmarshal)
+	e1 := dentry{prefix: 7, key: []byte("a"), value: []byte(`"1"`)}
+	e2 := dentry{prefix: 7, key: []byte("b"), value: []byte(`"2"`)}
+	e3 := dentry{prefix: 7 | 1<<10, key: []byte("c"), value: []byte(`"3"`)}
+	root := int64(0)
+	for _, e := range []dentry{e1, e2, e3} {
+		if root, err = m.set(root, 0, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m = m.At(root)
+	for _, e := range []dentry{e1, e2, e3} {
+		v, ok, err := m.lookup(e.prefix, e.key)
+		assert.Equal(t, nil, err, "m.lookup(%s)", e.key)
+		assert.Equal(t, true, ok, "m.lookup(%s)", e.key)
+		assert.EqualString(t, string(e.value), string(v), "m.lookup(%s)", e.key)
+	}
+	n, err := m.delete(root, e1.prefix, 0, e1.key)
+	assert.Equal(t, nil, err, "m.delete(a)")
+	if root, err = m.save(*n); err != nil {
+		t.Fatal(err)
+	}
+	_, ok, _ := m.lookup(e1.prefix, e1.key)
+	assert.Equal(t, true, ok, "m.lookup(a)")
+	_, ok, _ = m.At(root).lookup(e1.prefix, e1.key)
+	assert.Equal(t, false, ok, "m.At(root).lookup(a)")
+	_, ok, _ = m.At(root).lookup(e2.prefix, e2.key)
+	assert.Equal(t, true, ok, "m.At(root).lookup(b)")
+}
+
+func TestDiskMapInvalidOffset(t *testing.T) {
+	store, err := OpenFileNodeStore(filepath.Join(t.TempDir(), "nodes"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	m := DiskMapWith[string, int](store, 0, json.Marshal, json.Unmarshal)
+	for i := 0; i < 4; i++ {
+		if m, err = m.Set(fmt.Sprint("key", i), i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// offsets inside nodes read garbage lengths that must be rejected
+	// instead of allocated
+	for offset := int64(9); offset < store.size; offset++ {
+		m.At(offset).Len()
+		_, err := m.At(offset).Has("key1")
+		assert.Equal(t, true, err == nil || err == InvalidNodeOffset, "m.At(%d).Has(key1) err %v", offset, err)
+	}
+	_, err = m.At(store.size + 100).Len()
+	assert.Equal(t, InvalidNodeOffset, err, "m.At(beyond end).Len()")
+
+	// a node referring to itself must be rejected instead of recursing
+	self := store.size
+	offset, err := store.Append(dnode{bits: 1, entries: []dentry{{offset: self}}}.encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = m.At(offset).Len()
+	assert.Equal(t, InvalidNodeOffset, err, "m.At(self).Len()")
+
+	// a bitmap that does not match the entries must be rejected instead of
+	// indexed
+	offset, err = store.Append(dnode{bits: 0xffffffff}.encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = m.At(offset).Has("key1")
+	assert.Equal(t, InvalidNodeOffset, err, "m.At(bad bitmap).Has(key1)")
+	_, err = m.At(offset).Set("key1", 1)
+	assert.Equal(t, InvalidNodeOffset, err, "m.At(bad bitmap).Set(key1)")
+}
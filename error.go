@@ -7,3 +7,15 @@ func (e MapError) Error() string {
 }
 
 const UnhashableKeyType = MapError("Unhashable Key Type")
+
+const InvalidNodeStore = MapError("Invalid Node Store")
+
+const InvalidNodeOffset = MapError("Invalid Node Offset")
+
+const IndexOutOfRange = MapError("Index Out Of Range")
+
+const InvalidPath = MapError("Invalid Path")
+
+const LengthMismatch = MapError("Length Mismatch")
+
+const InvalidCursor = MapError("Invalid Cursor")
@@ -1,8 +1,11 @@
 package immutable_test
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"hash/maphash"
+	"strings"
 
 	"github.com/reactivego/immutable"
 )
@@ -70,6 +73,24 @@ func ExampleMapX_Set() {
 	// {Theme This is a topic about theme}
 }
 
+func ExampleMapH() {
+	// Key is not comparable, so it needs both a hash and an equal function.
+	hash := func(key []byte) uint64 { return maphash.Bytes(seed, key) }
+
+	m := immutable.MapWithHasher[[]byte, string](hash, bytes.Equal)
+
+	m = m.Set([]byte("first"), "Hello").Set([]byte("second"), "World!")
+	m = m.Set([]byte("first"), "Goodbye")
+
+	fmt.Println(m.Len())
+	fmt.Println(m.Get([]byte("first")), m.Get([]byte("second")))
+	// Output:
+	// 2
+	// Goodbye World!
+}
+
+var seed = maphash.MakeSeed()
+
 func ExampleStore_Put() {
 	// Topic is an example of data where the key (i.e. Name) is part of the data.
 	type Topic struct{ Name, Description string }
@@ -86,3 +107,33 @@ func ExampleStore_Put() {
 	// {Name:Mammalia Description:This topic is about mammals}
 	// {Name:Aves Description:This topic is about birds.}
 }
+
+func ExampleOrderedMap() {
+	m := immutable.OrderedMapWith[string, int](strings.Compare)
+
+	m = m.Set("second", 456).Set("first", 123).Set("third", 789)
+
+	m.Range(func(key string, value int) bool {
+		fmt.Println(key, value)
+		return true
+	})
+	fmt.Println(m.Floor("sz"))
+	// Output:
+	// first 123
+	// second 456
+	// third 789
+	// second 456 true
+}
+
+func ExampleVector() {
+	var v immutable.Vector[string]
+
+	v = v.Append("a", "b", "c")
+	w := v.Set(1, "B").Concat(v.Slice(0, 2))
+
+	fmt.Println(v)
+	fmt.Println(w, w.Len(), w.Get(3))
+	// Output:
+	// [a, b, c]
+	// [a, B, c, a, b] 5 a
+}
@@ -0,0 +1,235 @@
+package immutable
+
+import (
+	"fmt"
+	"strings"
+)
+
+// hnode is a node of a persistent leftist heap. The rank of a node is the
+// length of its right spine, which is kept shortest by swapping children.
+type hnode[T any] struct {
+	value       T
+	left, right *hnode[T]
+	rank        int
+	size        int
+}
+
+func (n *hnode[T]) len() int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+func (n *hnode[T]) ranked() int {
+	if n == nil {
+		return 0
+	}
+	return n.rank
+}
+
+func hmerge[T any](a, b *hnode[T], less func(T, T) bool) *hnode[T] {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if less(b.value, a.value) {
+		a, b = b, a
+	}
+	l, r := a.left, hmerge(a.right, b, less)
+	if l.ranked() < r.ranked() {
+		l, r = r, l
+	}
+	return &hnode[T]{a.value, l, r, r.ranked() + 1, a.size + b.size}
+}
+
+func (n *hnode[T]) foreach(f func(T) bool) bool {
+	for n != nil {
+		if !f(n.value) || !n.left.foreach(f) {
+			return false
+		}
+		n = n.right
+	}
+	return true
+}
+
+// Heap is a persistent immutable min-heap implemented as a leftist heap.
+// Values are ordered by an external less function. Push, Pop and Merge take
+// O(log n) time and Peek takes O(1) time.
+type Heap[T any] struct {
+	root *hnode[T]
+	less func(T, T) bool
+}
+
+func HeapWith[T any](less func(a, b T) bool) Heap[T] {
+	return Heap[T]{nil, less}
+}
+
+// Len returns the number of values in the heap.
+func (a Heap[T]) Len() int {
+	return a.root.len()
+}
+
+// Push returns a copy of the heap with the value added to it.
+func (a Heap[T]) Push(value T) Heap[T] {
+	return Heap[T]{hmerge(a.root, &hnode[T]{value: value, rank: 1, size: 1}, a.less), a.less}
+}
+
+// Peek returns the smallest value along with the value true. It returns
+// (zero, false) when the heap is empty.
+func (a Heap[T]) Peek() (T, bool) {
+	if a.root == nil {
+		var zero T
+		return zero, false
+	}
+	return a.root.value, true
+}
+
+// Pop returns the smallest value and the heap without that value along with
+// the value true. It returns (zero, a, false) when the heap is empty.
+func (a Heap[T]) Pop() (T, Heap[T], bool) {
+	if a.root == nil {
+		var zero T
+		return zero, a, false
+	}
+	return a.root.value, Heap[T]{hmerge(a.root.left, a.root.right, a.less), a.less}, true
+}
+
+// Merge returns a heap with the values of both heaps. The less function of a
+// is used for the result.
+func (a Heap[T]) Merge(b Heap[T]) Heap[T] {
+	return Heap[T]{hmerge(a.root, b.root, a.less), a.less}
+}
+
+// Range calls the given function for every value in the heap. The values are
+// not visited in sorted order.
+func (a Heap[T]) Range(f func(T) bool) {
+	a.root.foreach(f)
+}
+
+// String returns a string representation of the values in the heap.
+func (a Heap[T]) String() string {
+	var b strings.Builder
+	b.WriteByte('[')
+	f := "%+v"
+	a.root.foreach(func(v T) bool {
+		_, err := fmt.Fprintf(&b, f, v)
+		f = ", %+v"
+		return err == nil
+	})
+	b.WriteByte(']')
+	return b.String()
+}
+
+// hitem is an entry of an IndexedHeap. The seq of an item in the heap is
+// compared with the seq of the item in the map to detect outdated items.
+type hitem[I comparable, T any] struct {
+	id       I
+	priority T
+	seq      uint64
+}
+
+// slack is the number of outdated items an IndexedHeap tolerates on top of
+// the number of current items before it rebuilds its heap. It keeps small
+// heaps from being rebuilt after every few updates.
+const slack = 32
+
+// IndexedHeap is a persistent immutable min-heap of ids with a priority. A
+// companion Map holds the current priority of every id, so the priority of an
+// id can be changed with Set. Items that became outdated by Set or Del are
+// dropped lazily when they reach the top of the heap.
+type IndexedHeap[I comparable, T any] struct {
+	heap  Heap[hitem[I, T]]
+	items Map[I, hitem[I, T]]
+	seq   uint64
+}
+
+func IndexedHeapWith[I comparable, T any](less func(a, b T) bool) IndexedHeap[I, T] {
+	return IndexedHeap[I, T]{heap: HeapWith(func(a, b hitem[I, T]) bool {
+		return less(a.priority, b.priority)
+	})}
+}
+
+// clean drops outdated items from the top of the heap and rebuilds the heap
+// when more than half of its items are outdated.
+func (a IndexedHeap[I, T]) clean() IndexedHeap[I, T] {
+	if a.heap.Len() > 2*a.items.Len()+slack {
+		heap := Heap[hitem[I, T]]{nil, a.heap.less}
+		a.items.Range(func(_ I, item hitem[I, T]) bool {
+			heap = heap.Push(item)
+			return true
+		})
+		a.heap = heap
+	}
+	for {
+		top, ok := a.heap.Peek()
+		if !ok {
+			return a
+		}
+		if item, ok := a.items.Lookup(top.id); ok && item.seq == top.seq {
+			return a
+		}
+		_, a.heap, _ = a.heap.Pop()
+	}
+}
+
+// Len returns the number of ids in the heap.
+func (a IndexedHeap[I, T]) Len() int {
+	return a.items.Len()
+}
+
+// Lookup returns the priority of the given id along with the value true
+// when the id is present. Otherwise it returns (zero, false).
+func (a IndexedHeap[I, T]) Lookup(id I) (T, bool) {
+	item, ok := a.items.Lookup(id)
+	return item.priority, ok
+}
+
+// Has returns true when the given id is present.
+func (a IndexedHeap[I, T]) Has(id I) bool {
+	return a.items.Has(id)
+}
+
+// Set returns a copy of the heap with the priority of the id set. The id is
+// added when it is not present, otherwise its priority is changed.
+func (a IndexedHeap[I, T]) Set(id I, priority T) IndexedHeap[I, T] {
+	a.seq++
+	item := hitem[I, T]{id, priority, a.seq}
+	a.heap = a.heap.Push(item)
+	a.items = a.items.Set(id, item)
+	return a.clean()
+}
+
+// Del returns a copy of the heap with the id removed.
+func (a IndexedHeap[I, T]) Del(id I) IndexedHeap[I, T] {
+	a.items = a.items.Del(id)
+	return a.clean()
+}
+
+// Peek returns the id with the smallest priority and its priority along with
+// the value true. It returns (zero, zero, false) when the heap is empty.
+func (a IndexedHeap[I, T]) Peek() (I, T, bool) {
+	top, ok := a.heap.Peek()
+	return top.id, top.priority, ok
+}
+
+// Pop returns the id with the smallest priority, its priority and the heap
+// without that id along with the value true. It returns (zero, zero, a,
+// false) when the heap is empty.
+func (a IndexedHeap[I, T]) Pop() (I, T, IndexedHeap[I, T], bool) {
+	top, heap, ok := a.heap.Pop()
+	if !ok {
+		return top.id, top.priority, a, false
+	}
+	a.heap = heap
+	a.items = a.items.Del(top.id)
+	return top.id, top.priority, a.clean(), true
+}
+
+// Range calls the given function for every id,priority pair present. The
+// pairs are not visited in priority order.
+func (a IndexedHeap[I, T]) Range(f func(I, T) bool) {
+	a.items.Range(func(id I, item hitem[I, T]) bool { return f(id, item.priority) })
+}
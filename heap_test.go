@@ -0,0 +1,82 @@
+package immutable
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestHeap(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	less := func(a, b int) bool { return a < b }
+	h0 := HeapWith(less)
+	a, b := h0, h0
+	var ref []int
+	for i := 0; i < 500; i++ {
+		x := rnd.Intn(1000)
+		a, ref = a.Push(x), append(ref, x)
+		x = rnd.Intn(1000)
+		b, ref = b.Push(x), append(ref, x)
+	}
+	sort.Ints(ref)
+
+	h := a.Merge(b)
+	assert.EqualInt(t, 1000, h.Len(), "h.Len()")
+	assert.EqualInt(t, 500, a.Len(), "a.Len()")
+	for i, exp := range ref {
+		top, _ := h.Peek()
+		v, rest, ok := h.Pop()
+		assert.Equal(t, true, ok, "h.Pop() #%d", i)
+		assert.EqualInt(t, exp, v, "h.Pop() #%d", i)
+		assert.EqualInt(t, exp, top, "h.Peek() #%d", i)
+		h = rest
+	}
+	_, _, ok := h.Pop()
+	assert.Equal(t, false, ok, "h.Pop() on empty heap")
+	assert.EqualString(t, "[1]", h0.Push(1).String(), "h0.Push(1).String()")
+}
+
+func TestIndexedHeap(t *testing.T) {
+	h := IndexedHeapWith[string](func(a, b int) bool { return a < b })
+	h = h.Set("a", 5).Set("b", 3).Set("c", 7)
+
+	id, p, ok := h.Peek()
+	assert.Equal(t, true, ok, "h.Peek()")
+	assert.EqualString(t, "b", id, "h.Peek() id")
+	assert.EqualInt(t, 3, p, "h.Peek() priority")
+
+	// decrease the priority of c below b
+	h1 := h.Set("c", 1)
+	id, _, _ = h1.Peek()
+	assert.EqualString(t, "c", id, "h1.Peek() id")
+	id, _, _ = h.Peek()
+	assert.EqualString(t, "b", id, "h.Peek() id")
+	assert.EqualInt(t, 3, h1.Len(), "h1.Len()")
+
+	// removing b makes a the top
+	h2 := h.Del("b")
+	id, _, _ = h2.Peek()
+	assert.EqualString(t, "a", id, "h2.Peek() id")
+
+	var ids []string
+	for {
+		var id string
+		var ok bool
+		if id, _, h1, ok = h1.Pop(); !ok {
+			break
+		}
+		ids = append(ids, id)
+	}
+	assert.EqualInt(t, 3, len(ids), "len(ids)")
+	for i, exp := range []string{"c", "b", "a"} {
+		assert.EqualString(t, exp, ids[i], "ids[%d]", i)
+	}
+
+	// many updates must not let outdated items pile up
+	for i := 0; i < 1000; i++ {
+		h = h.Set("a", 1000-i)
+	}
+	p, _ = h.Lookup("a")
+	assert.EqualInt(t, 1, p, "h.Lookup(a)")
+	assert.Equal(t, true, h.heap.Len() < 2*h.Len()+branching+1, "h.heap.Len() %d", h.heap.Len())
+}
@@ -0,0 +1,122 @@
+package immutable
+
+import (
+	"fmt"
+	"strings"
+)
+
+// interval holds the end and the value of a range in an IntervalMap.
+type interval[T any, V comparable] struct {
+	hi    T
+	value V
+}
+
+// IntervalMap is a persistent immutable map from half open ranges [lo, hi)
+// to values. The ranges in the map never overlap, inserting a range
+// overwrites the overlapping parts of existing ranges. Adjacent ranges with
+// equal values are coalesced into a single range. The ranges are kept in an
+// OrderedMap keyed by the start of every range, so the points of a range are
+// ordered by an external compare function.
+type IntervalMap[T any, V comparable] struct {
+	ranges OrderedMap[T, interval[T, V]]
+}
+
+func IntervalMapWith[T any, V comparable](compare func(T, T) int) IntervalMap[T, V] {
+	return IntervalMap[T, V]{OrderedMapWith[T, interval[T, V]](compare)}
+}
+
+// Len returns the number of ranges that are present.
+func (a IntervalMap[T, V]) Len() int {
+	return a.ranges.Len()
+}
+
+// Stab returns the value of the range that contains the given point along
+// with the value true. It returns (zero, false) when no range contains the
+// point.
+func (a IntervalMap[T, V]) Stab(point T) (V, bool) {
+	if n := a.ranges.root.floor(point, true, a.ranges.compare); n != nil && a.ranges.compare(point, n.value.hi) < 0 {
+		return n.value.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Overlapping calls the given function in ascending order for every range
+// that overlaps with the range [lo, hi).
+func (a IntervalMap[T, V]) Overlapping(lo, hi T, f func(T, T, V) bool) {
+	compare := a.ranges.compare
+	if compare(lo, hi) >= 0 {
+		return
+	}
+	if n := a.ranges.root.floor(lo, false, compare); n != nil && compare(n.value.hi, lo) > 0 {
+		if !f(n.key, n.value.hi, n.value.value) {
+			return
+		}
+	}
+	a.ranges.RangeFrom(lo, hi, func(lo T, r interval[T, V]) bool {
+		return f(lo, r.hi, r.value)
+	})
+}
+
+// Range calls the given function in ascending order for every range present.
+func (a IntervalMap[T, V]) Range(f func(T, T, V) bool) {
+	a.ranges.Range(func(lo T, r interval[T, V]) bool {
+		return f(lo, r.hi, r.value)
+	})
+}
+
+// String returns a string representation of the ranges and their values.
+func (a IntervalMap[T, V]) String() string {
+	var b strings.Builder
+	b.WriteByte('{')
+	f := "[%+v, %+v):%+v"
+	a.Range(func(lo, hi T, v V) bool {
+		_, err := fmt.Fprintf(&b, f, lo, hi, v)
+		f = ", [%+v, %+v):%+v"
+		return err == nil
+	})
+	b.WriteByte('}')
+	return b.String()
+}
+
+// Insert returns a copy of the IntervalMap with the range [lo, hi) mapped to
+// the value. Parts of existing ranges that overlap with [lo, hi) are
+// overwritten. Inserting an empty range returns the map unchanged.
+func (a IntervalMap[T, V]) Insert(lo, hi T, value V) IntervalMap[T, V] {
+	compare := a.ranges.compare
+	if compare(lo, hi) >= 0 {
+		return a
+	}
+	m := a.Delete(lo, hi).ranges
+	if n := m.root.floor(lo, false, compare); n != nil && compare(n.value.hi, lo) == 0 && n.value.value == value {
+		m, lo = m.Del(n.key), n.key
+	}
+	if r, ok := m.Lookup(hi); ok && r.value == value {
+		m, hi = m.Del(hi), r.hi
+	}
+	return IntervalMap[T, V]{m.Set(lo, interval[T, V]{hi, value})}
+}
+
+// Delete returns a copy of the IntervalMap with the range [lo, hi) removed.
+// Ranges that partially overlap with [lo, hi) are truncated.
+func (a IntervalMap[T, V]) Delete(lo, hi T) IntervalMap[T, V] {
+	compare := a.ranges.compare
+	if compare(lo, hi) >= 0 {
+		return a
+	}
+	m := a.ranges
+	if n := m.root.floor(lo, false, compare); n != nil && compare(n.value.hi, lo) > 0 {
+		m = m.Set(n.key, interval[T, V]{lo, n.value.value})
+		if compare(n.value.hi, hi) > 0 {
+			m = m.Set(hi, n.value)
+		}
+	}
+	a.ranges.RangeFrom(lo, hi, func(key T, r interval[T, V]) bool {
+		m = m.Del(key)
+		if compare(r.hi, hi) > 0 {
+			m = m.Set(hi, r)
+		}
+		return true
+	})
+	return IntervalMap[T, V]{m}
+}
@@ -0,0 +1,75 @@
+package immutable
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestIntervalMap(t *testing.T) {
+	m0 := IntervalMapWith[int, string](compareInt)
+	m1 := m0.Insert(0, 10, "a").Insert(20, 30, "b")
+	m2 := m1.Insert(5, 25, "c")
+	m3 := m2.Insert(10, 20, "a")
+	m4 := m2.Delete(7, 22)
+
+	assert.EqualString(t, "{[0, 10):a, [20, 30):b}", m1.String(), "m1.String()")
+	assert.EqualString(t, "{[0, 5):a, [5, 25):c, [25, 30):b}", m2.String(), "m2.String()")
+	assert.EqualString(t, "{[0, 5):a, [5, 10):c, [10, 20):a, [20, 25):c, [25, 30):b}", m3.String(), "m3.String()")
+	assert.EqualString(t, "{[0, 5):a, [5, 7):c, [22, 25):c, [25, 30):b}", m4.String(), "m4.String()")
+
+	// adjacent ranges with equal values are coalesced
+	m5 := m1.Insert(10, 20, "a")
+	assert.EqualString(t, "{[0, 20):a, [20, 30):b}", m5.String(), "m5.String()")
+	m6 := m5.Insert(20, 30, "a")
+	assert.EqualString(t, "{[0, 30):a}", m6.String(), "m6.String()")
+	assert.EqualInt(t, 1, m6.Len(), "m6.Len()")
+
+	v, ok := m2.Stab(5)
+	assert.Equal(t, true, ok, "m2.Stab(5)")
+	assert.EqualString(t, "c", v, "m2.Stab(5)")
+	v, _ = m2.Stab(4)
+	assert.EqualString(t, "a", v, "m2.Stab(4)")
+	_, ok = m2.Stab(30)
+	assert.Equal(t, false, ok, "m2.Stab(30)")
+
+	var got []string
+	m3.Overlapping(7, 21, func(lo, hi int, v string) bool {
+		got = append(got, v)
+		return true
+	})
+	assert.EqualInt(t, 3, len(got), "m3.Overlapping(7, 21)")
+	assert.EqualString(t, "c", got[0], "m3.Overlapping(7, 21) #0")
+	assert.EqualString(t, "a", got[1], "m3.Overlapping(7, 21) #1")
+	assert.EqualString(t, "c", got[2], "m3.Overlapping(7, 21) #2")
+}
+
+func TestIntervalMapRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	m := IntervalMapWith[int, int](compareInt)
+	var ref [100]int // 0 means no value
+	for i := 0; i < 2000; i++ {
+		lo := rnd.Intn(100)
+		hi := lo + rnd.Intn(100-lo+1)
+		v := rnd.Intn(3)
+		if v == 0 {
+			m = m.Delete(lo, hi)
+		} else {
+			m = m.Insert(lo, hi, v)
+		}
+		for p := lo; p < hi; p++ {
+			ref[p] = v
+		}
+	}
+	for p, exp := range ref {
+		v, ok := m.Stab(p)
+		assert.Equal(t, exp != 0, ok, "m.Stab(%d)", p)
+		assert.EqualInt(t, exp, v, "m.Stab(%d)", p)
+	}
+	prevhi, prevv := -1, 0
+	m.Range(func(lo, hi, v int) bool {
+		assert.Equal(t, true, lo < hi, "range [%d, %d) not empty", lo, hi)
+		assert.Equal(t, false, prevhi == lo && prevv == v, "range [%d, %d) not coalesced", lo, hi)
+		prevhi, prevv = hi, v
+		return true
+	})
+}
@@ -0,0 +1,322 @@
+package immutable
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+type integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// pnode is a node of a persistent big-endian Patricia trie. A leaf has a
+// mask of zero and holds a key,value pair where prefix is the key converted
+// to 64 bits. A branch has a mask with only the highest bit set in which the
+// keys of its left and right subtree differ. The prefix of a branch holds the
+// bits above the mask that are shared by all keys in the branch. Keys in the
+// left subtree have the mask bit cleared and are therefore smaller than the
+// keys in the right subtree.
+type pnode[K integer, V any] struct {
+	prefix      uint64
+	mask        uint64
+	left, right *pnode[K, V]
+	key         K
+	value       V
+	size        int
+}
+
+// bits64 converts the key to 64 bits such that the order of the keys is
+// preserved. For signed integer types the sign bit is flipped.
+func bits64[K integer](key K) uint64 {
+	var zero K
+	if ^zero < 0 {
+		return uint64(key) ^ 1<<63
+	}
+	return uint64(key)
+}
+
+func maskAbove(key, mask uint64) uint64 {
+	return key & (^(mask - 1) ^ mask)
+}
+
+func branchingBit(p1, p2 uint64) uint64 {
+	return 1 << (63 - bits.LeadingZeros64(p1^p2))
+}
+
+func (n *pnode[K, V]) len() int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+func (n *pnode[K, V]) matches(prefix uint64) bool {
+	return maskAbove(prefix, n.mask) == n.prefix
+}
+
+func pbranch[K integer, V any](prefix, mask uint64, left, right *pnode[K, V]) *pnode[K, V] {
+	switch {
+	case left == nil:
+		return right
+	case right == nil:
+		return left
+	}
+	return &pnode[K, V]{prefix: prefix, mask: mask, left: left, right: right, size: left.size + right.size}
+}
+
+// pjoin returns a branch holding the trees t0 and t1 with the different
+// prefixes p0 and p1.
+func pjoin[K integer, V any](p0 uint64, t0 *pnode[K, V], p1 uint64, t1 *pnode[K, V]) *pnode[K, V] {
+	m := branchingBit(p0, p1)
+	if p0&m == 0 {
+		return pbranch(maskAbove(p0, m), m, t0, t1)
+	}
+	return pbranch(maskAbove(p0, m), m, t1, t0)
+}
+
+func (n *pnode[K, V]) lookup(key uint64) *pnode[K, V] {
+	for n != nil && n.mask != 0 {
+		if !n.matches(key) {
+			return nil
+		}
+		if key&n.mask == 0 {
+			n = n.left
+		} else {
+			n = n.right
+		}
+	}
+	if n != nil && n.prefix == key {
+		return n
+	}
+	return nil
+}
+
+// insert returns the tree with the leaf inserted. When the key of the leaf
+// is already present, the existing leaf is kept unless replace is true.
+func (n *pnode[K, V]) insert(leaf *pnode[K, V], replace bool) *pnode[K, V] {
+	switch {
+	case n == nil:
+		return leaf
+	case n.mask == 0:
+		if n.prefix != leaf.prefix {
+			return pjoin(leaf.prefix, leaf, n.prefix, n)
+		}
+		if replace {
+			return leaf
+		}
+		return n
+	case !n.matches(leaf.prefix):
+		return pjoin(leaf.prefix, leaf, n.prefix, n)
+	case leaf.prefix&n.mask == 0:
+		return pbranch(n.prefix, n.mask, n.left.insert(leaf, replace), n.right)
+	}
+	return pbranch(n.prefix, n.mask, n.left, n.right.insert(leaf, replace))
+}
+
+func (n *pnode[K, V]) delete(key uint64) *pnode[K, V] {
+	switch {
+	case n == nil:
+		return nil
+	case n.mask == 0:
+		if n.prefix == key {
+			return nil
+		}
+		return n
+	case !n.matches(key):
+		return n
+	case key&n.mask == 0:
+		if left := n.left.delete(key); left != n.left {
+			return pbranch(n.prefix, n.mask, left, n.right)
+		}
+		return n
+	}
+	if right := n.right.delete(key); right != n.right {
+		return pbranch(n.prefix, n.mask, n.left, right)
+	}
+	return n
+}
+
+// union returns a tree with the entries of both trees. For keys present in
+// both trees the entry of n is used.
+func (n *pnode[K, V]) union(o *pnode[K, V]) *pnode[K, V] {
+	switch {
+	case n == nil:
+		return o
+	case o == nil:
+		return n
+	case n.mask == 0:
+		return o.insert(n, true)
+	case o.mask == 0:
+		return n.insert(o, false)
+	case n.mask > o.mask:
+		if !n.matches(o.prefix) {
+			return pjoin(n.prefix, n, o.prefix, o)
+		}
+		if o.prefix&n.mask == 0 {
+			return pbranch(n.prefix, n.mask, n.left.union(o), n.right)
+		}
+		return pbranch(n.prefix, n.mask, n.left, n.right.union(o))
+	case n.mask < o.mask:
+		if !o.matches(n.prefix) {
+			return pjoin(n.prefix, n, o.prefix, o)
+		}
+		if n.prefix&o.mask == 0 {
+			return pbranch(o.prefix, o.mask, n.union(o.left), o.right)
+		}
+		return pbranch(o.prefix, o.mask, o.left, n.union(o.right))
+	case n.prefix == o.prefix:
+		return pbranch(n.prefix, n.mask, n.left.union(o.left), n.right.union(o.right))
+	}
+	return pjoin(n.prefix, n, o.prefix, o)
+}
+
+// intersection returns a tree with the entries of n whose keys are also
+// present in o.
+func (n *pnode[K, V]) intersection(o *pnode[K, V]) *pnode[K, V] {
+	switch {
+	case n == nil || o == nil:
+		return nil
+	case n.mask == 0:
+		if o.lookup(n.prefix) != nil {
+			return n
+		}
+		return nil
+	case o.mask == 0:
+		return n.lookup(o.prefix)
+	case n.mask > o.mask:
+		if !n.matches(o.prefix) {
+			return nil
+		}
+		if o.prefix&n.mask == 0 {
+			return n.left.intersection(o)
+		}
+		return n.right.intersection(o)
+	case n.mask < o.mask:
+		if !o.matches(n.prefix) {
+			return nil
+		}
+		if n.prefix&o.mask == 0 {
+			return n.intersection(o.left)
+		}
+		return n.intersection(o.right)
+	case n.prefix == o.prefix:
+		return pbranch(n.prefix, n.mask, n.left.intersection(o.left), n.right.intersection(o.right))
+	}
+	return nil
+}
+
+func (n *pnode[K, V]) foreach(f func(K, V) bool) bool {
+	if n == nil {
+		return true
+	}
+	if n.mask == 0 {
+		return f(n.key, n.value)
+	}
+	return n.left.foreach(f) && n.right.foreach(f)
+}
+
+// IntMap is a persistent immutable map with integer keys implemented as a
+// big-endian Patricia trie. Unlike Map it uses all 64 bits of a key, so keys
+// never collide, and it keeps its keys in ascending order. Union and
+// Intersection work on whole subtrees at once.
+type IntMap[K integer, V any] struct{ root *pnode[K, V] }
+
+// Len returns the number of entries that are present.
+func (a IntMap[K, V]) Len() int {
+	return a.root.len()
+}
+
+// Lookup returns the value of an entry associated with a given key along with
+// the value true when the key is present. Otherwise it returns (zero, false).
+func (a IntMap[K, V]) Lookup(key K) (V, bool) {
+	if n := a.root.lookup(bits64(key)); n != nil {
+		return n.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Has returns true when an entry with the given key is present.
+func (a IntMap[K, V]) Has(key K) bool {
+	return a.root.lookup(bits64(key)) != nil
+}
+
+// Get returns the value for the entry with the given key or zero value
+// when it is not present.
+func (a IntMap[K, V]) Get(key K) V {
+	v, _ := a.Lookup(key)
+	return v
+}
+
+// Range calls the given function for every key,value pair present in
+// ascending key order.
+func (a IntMap[K, V]) Range(f func(K, V) bool) {
+	a.root.foreach(f)
+}
+
+// String returns a string representation of the key,value pairs present.
+func (a IntMap[K, V]) String() string {
+	var b strings.Builder
+	b.WriteByte('{')
+	f := "%+v:%+v"
+	a.root.foreach(func(k K, v V) bool {
+		_, err := fmt.Fprintf(&b, f, k, v)
+		f = ", %+v:%+v"
+		return err == nil
+	})
+	b.WriteByte('}')
+	return b.String()
+}
+
+// Set returns a copy of the IntMap with the given key,value pair inserted.
+func (a IntMap[K, V]) Set(key K, value V) IntMap[K, V] {
+	return IntMap[K, V]{a.root.insert(&pnode[K, V]{prefix: bits64(key), key: key, value: value, size: 1}, true)}
+}
+
+// Del returns a copy of the IntMap with the entry for the key removed.
+func (a IntMap[K, V]) Del(key K) IntMap[K, V] {
+	return IntMap[K, V]{a.root.delete(bits64(key))}
+}
+
+// Min returns the entry with the smallest key along with the value true.
+// When the map is empty it returns (zero, zero, false).
+func (a IntMap[K, V]) Min() (K, V, bool) {
+	n := a.root
+	for n != nil && n.mask != 0 {
+		n = n.left
+	}
+	return pentryOf(n)
+}
+
+// Max returns the entry with the largest key along with the value true.
+// When the map is empty it returns (zero, zero, false).
+func (a IntMap[K, V]) Max() (K, V, bool) {
+	n := a.root
+	for n != nil && n.mask != 0 {
+		n = n.right
+	}
+	return pentryOf(n)
+}
+
+// Union returns a map with the entries of both maps. For keys present in
+// both maps the value of a is used.
+func (a IntMap[K, V]) Union(b IntMap[K, V]) IntMap[K, V] {
+	return IntMap[K, V]{a.root.union(b.root)}
+}
+
+// Intersection returns a map with the entries of a whose keys are also
+// present in b.
+func (a IntMap[K, V]) Intersection(b IntMap[K, V]) IntMap[K, V] {
+	return IntMap[K, V]{a.root.intersection(b.root)}
+}
+
+func pentryOf[K integer, V any](n *pnode[K, V]) (K, V, bool) {
+	if n == nil {
+		var key K
+		var value V
+		return key, value, false
+	}
+	return n.key, n.value, true
+}
@@ -0,0 +1,86 @@
+package immutable
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestIntMap(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	var m IntMap[int64, int]
+	ref := map[int64]int{}
+	for i := 0; i < 3000; i++ {
+		// keys that only differ in their high bits
+		k := int64(rnd.Intn(50)-25) << 40
+		if rnd.Intn(3) == 0 {
+			m = m.Del(k)
+			delete(ref, k)
+		} else {
+			m = m.Set(k, i)
+			ref[k] = i
+		}
+	}
+	assert.EqualInt(t, len(ref), m.Len(), "m.Len()")
+
+	keys := make([]int64, 0, len(ref))
+	for k := range ref {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	i := 0
+	m.Range(func(k int64, v int) bool {
+		assert.Equal(t, keys[i], k, "m.Range() #%d", i)
+		assert.EqualInt(t, ref[k], v, "m.Range() #%d", i)
+		i++
+		return true
+	})
+	assert.EqualInt(t, len(keys), i, "m.Range() count")
+
+	min, _, _ := m.Min()
+	max, _, _ := m.Max()
+	assert.Equal(t, keys[0], min, "m.Min()")
+	assert.Equal(t, keys[len(keys)-1], max, "m.Max()")
+	assert.Equal(t, false, m.Has(1), "m.Has(1)")
+}
+
+func TestIntMapUnionIntersection(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	var a, b IntMap[uint64, string]
+	ina, inb := map[uint64]bool{}, map[uint64]bool{}
+	for i := 0; i < 500; i++ {
+		k := uint64(rnd.Intn(1000)) << uint(rnd.Intn(3)*30)
+		a, ina[k] = a.Set(k, "a"), true
+		k = uint64(rnd.Intn(1000)) << uint(rnd.Intn(3)*30)
+		b, inb[k] = b.Set(k, "b"), true
+	}
+	union, intersection := a.Union(b), a.Intersection(b)
+
+	nu, ni := 0, 0
+	for k := range ina {
+		nu++
+		assert.EqualString(t, "a", union.Get(k), "union.Get(%d)", k)
+		if inb[k] {
+			ni++
+			assert.EqualString(t, "a", intersection.Get(k), "intersection.Get(%d)", k)
+		} else {
+			assert.Equal(t, false, intersection.Has(k), "intersection.Has(%d)", k)
+		}
+	}
+	for k := range inb {
+		if !ina[k] {
+			nu++
+			assert.EqualString(t, "b", union.Get(k), "union.Get(%d)", k)
+		}
+	}
+	assert.EqualInt(t, nu, union.Len(), "union.Len()")
+	assert.EqualInt(t, ni, intersection.Len(), "intersection.Len()")
+
+	var prev uint64
+	union.Range(func(k uint64, _ string) bool {
+		assert.Equal(t, true, prev <= k, "union.Range() order at %d", k)
+		prev = k
+		return true
+	})
+	assert.EqualString(t, "{-1:x, 0:y, 7:z}", IntMap[int8, string]{}.Set(7, "z").Set(-1, "x").Set(0, "y").String(), "String()")
+}
@@ -0,0 +1,148 @@
+package immutable
+
+// maxdepth is the maximum number of levels in an amt, the root level and a
+// level for every 5 bit group of the prefix up to the collision level.
+const maxdepth = collision/nextlevel + 1
+
+// frame is the position of an Iterator in a single level of the amt.
+type frame[K any, V any] struct {
+	node  amt[K, V]
+	index int
+}
+
+// Iterator is a pull iterator over the key,value pairs of a Map or Store. It
+// visits the pairs in the same order as Range. Instead of recursion it keeps
+// the path to the current position in a fixed size stack, so an Iterator
+// does not allocate while iterating and can be paused and resumed at will.
+type Iterator[K any, V any] struct {
+	root  amt[K, V]
+	stack [maxdepth]frame[K, V]
+	depth int
+	hash  func(K) uint32
+	equal func(K, K) bool
+}
+
+func (n amt[K, V]) iterator(hash func(K) uint32, equal func(K, K) bool) Iterator[K, V] {
+	it := Iterator[K, V]{root: n, depth: 1, hash: hash, equal: equal}
+	it.stack[0].node = n
+	return it
+}
+
+// Next returns the next key,value pair along with the value true. When all
+// pairs have been visited it returns (zero, zero, false).
+func (it *Iterator[K, V]) Next() (K, V, bool) {
+	for it.depth > 0 {
+		f := &it.stack[it.depth-1]
+		if f.index == len(f.node.entries) {
+			it.depth--
+			continue
+		}
+		e := f.node.entries[f.index]
+		f.index++
+		if a, ok := e.ref.(amt[K, V]); ok {
+			it.stack[it.depth] = frame[K, V]{a, 0}
+			it.depth++
+			continue
+		}
+		return refKey[K](e.ref), e.value, true
+	}
+	var key K
+	var value V
+	return key, value, false
+}
+
+// Peek returns the key,value pair that the next call to Next will return
+// without advancing the iterator.
+func (it *Iterator[K, V]) Peek() (K, V, bool) {
+	p := *it
+	return p.Next()
+}
+
+// Seek positions the iterator such that the next call to Next returns the
+// pair with the given key and returns true when the key is present. When the
+// key is not present the iterator is positioned at the first pair that Range
+// visits after the place where the key would be and Seek returns false.
+func (it *Iterator[K, V]) Seek(key K) bool {
+	return it.seek(it.hash(key), key)
+}
+
+func (it *Iterator[K, V]) seek(prefix uint32, key K) bool {
+	it.stack[0] = frame[K, V]{it.root, 0}
+	it.depth = 1
+	for shift := uint8(0); ; shift += nextlevel {
+		f := &it.stack[it.depth-1]
+		if shift == collision {
+			for i, e := range f.node.entries {
+				if it.equal(refKey[K](e.ref), key) {
+					f.index = i
+					return true
+				}
+			}
+			// the key would be appended to the collision level
+			f.index = len(f.node.entries)
+			return false
+		}
+		bitpos := bitpos(prefix, shift)
+		f.index = index(f.node.bits, bitpos)
+		if !present(f.node.bits, bitpos) {
+			return false
+		}
+		e := f.node.entries[f.index]
+		if a, ok := e.ref.(amt[K, V]); ok {
+			f.index++
+			it.stack[it.depth] = frame[K, V]{a, 0}
+			it.depth++
+			continue
+		}
+		if e.prefix == prefix && it.equal(refKey[K](e.ref), key) {
+			return true
+		}
+		if order(e.prefix) <= order(prefix) {
+			f.index++
+		}
+		return false
+	}
+}
+
+// Iterator returns an Iterator positioned before the first pair of the Map.
+func (a Map[K, V]) Iterator() Iterator[K, V] {
+	return a.iterator(hashOf[K], equals[K])
+}
+
+// Iterator returns an Iterator positioned before the first pair of the
+// Store. Seek takes a key rather than the data of an entry.
+func (a Store[D, K, V]) Iterator() Iterator[K, V] {
+	return a.iterator(hashOf[K], equals[K])
+}
+
+// SetIterator is a pull iterator over the keys of a Set that works in the
+// same way as Iterator.
+type SetIterator[K comparable] struct {
+	it Iterator[K, struct{}]
+}
+
+// Next returns the next key along with the value true. When all keys have
+// been visited it returns (zero, false).
+func (it *SetIterator[K]) Next() (K, bool) {
+	key, _, ok := it.it.Next()
+	return key, ok
+}
+
+// Peek returns the key that the next call to Next will return without
+// advancing the iterator.
+func (it *SetIterator[K]) Peek() (K, bool) {
+	key, _, ok := it.it.Peek()
+	return key, ok
+}
+
+// Seek positions the iterator such that the next call to Next returns the
+// given key and returns true when the key is present. Otherwise it works in
+// the same way as Iterator.Seek.
+func (it *SetIterator[K]) Seek(key K) bool {
+	return it.it.Seek(key)
+}
+
+// Iterator returns a SetIterator positioned before the first key of the Set.
+func (a Set[K]) Iterator() SetIterator[K] {
+	return SetIterator[K]{a.iterator(hashOf[K], equals[K])}
+}
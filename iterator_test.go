@@ -0,0 +1,105 @@
+package immutable
+
+import (
+	"testing"
+)
+
+func TestIterator(t *testing.T) {
+	m, ranged := collidingMap()
+
+	it := m.Iterator()
+	for i, exp := range ranged {
+		pk, _, pok := it.Peek()
+		k, v, ok := it.Next()
+		assert.Equal(t, true, ok && pok, "it.Next() #%d", i)
+		assert.Equal(t, exp, k, "it.Next() #%d", i)
+		assert.Equal(t, k, pk, "it.Peek() #%d", i)
+		assert.EqualInt(t, m.Get(k), v, "it.Next() value #%d", i)
+	}
+	_, _, ok := it.Next()
+	assert.Equal(t, false, ok, "it.Next() at end")
+	_, _, ok = it.Peek()
+	assert.Equal(t, false, ok, "it.Peek() at end")
+
+	for i := 0; i < len(ranged); i += 97 {
+		assert.Equal(t, true, it.Seek(ranged[i]), "it.Seek(%d)", ranged[i])
+		for j := i; j < i+5 && j < len(ranged); j++ {
+			k, _, _ := it.Next()
+			assert.Equal(t, ranged[j], k, "it.Next() after Seek(%d)", ranged[i])
+		}
+	}
+
+	// a missing key positions the iterator at the next key in trie order,
+	// present keys with the same prefix come before it
+	for _, missing := range []int64{1, 2, 30, 1<<32 + 1, 5 << 32} {
+		it.Seek(missing)
+		var exp int64
+		found := false
+		for _, k := range ranged {
+			if order(hash(k)) > order(hash(missing)) {
+				exp, found = k, true
+				break
+			}
+		}
+		k, _, ok := it.Next()
+		assert.Equal(t, found, ok, "it.Next() after Seek(%d)", missing)
+		assert.Equal(t, exp, k, "it.Next() after Seek(%d)", missing)
+	}
+	assert.Equal(t, false, it.Seek(-1), "it.Seek(-1)")
+
+	// a missing key in a collision level positions after the level
+	c := Map[int64, int]{}.Set(5, 5).Set(5+1<<32, 5).Set(6, 6)
+	cit := c.Iterator()
+	assert.Equal(t, false, cit.Seek(5+2<<32), "cit.Seek(5+2<<32)")
+	k, _, ok := cit.Next()
+	assert.Equal(t, true, ok, "cit.Next() after Seek(5+2<<32)")
+	assert.Equal(t, int64(6), k, "cit.Next() after Seek(5+2<<32)")
+
+	// a missing key with the prefix of a present key positions after that key
+	p := Map[int64, int]{}.Set(5, 5).Set(6, 6)
+	pit := p.Iterator()
+	assert.Equal(t, false, pit.Seek(5+1<<32), "pit.Seek(5+1<<32)")
+	k, _, ok = pit.Next()
+	assert.Equal(t, true, ok, "pit.Next() after Seek(5+1<<32)")
+	assert.Equal(t, int64(6), k, "pit.Next() after Seek(5+1<<32)")
+
+	var empty Map[string, int]
+	eit := empty.Iterator()
+	_, _, ok = eit.Next()
+	assert.Equal(t, false, ok, "empty.Iterator().Next()")
+	assert.Equal(t, false, eit.Seek("a"), "empty.Iterator().Seek(a)")
+}
+
+func TestIteratorZip(t *testing.T) {
+	var a, b Map[string, int]
+	for i := 0; i < 500; i++ {
+		a = a.Set(letterKey(i), i)
+		b = b.Set(letterKey(i), i*2)
+	}
+	ia, ib := a.Iterator(), b.Iterator()
+	for {
+		ka, va, oka := ia.Next()
+		kb, vb, okb := ib.Next()
+		assert.Equal(t, oka, okb, "ia.Next() and ib.Next()")
+		if !oka {
+			break
+		}
+		assert.Equal(t, ka, kb, "zipped keys")
+		assert.EqualInt(t, va*2, vb, "zipped values")
+	}
+}
+
+func TestSetIterator(t *testing.T) {
+	s := Set[string]{}.Put("a").Put("b").Put("c")
+	it := s.Iterator()
+	n := 0
+	for k, ok := it.Next(); ok; k, ok = it.Next() {
+		assert.Equal(t, true, s.Has(k), "s.Has(%s)", k)
+		n++
+	}
+	assert.EqualInt(t, 3, n, "keys iterated")
+	assert.Equal(t, true, it.Seek("b"), "it.Seek(b)")
+	k, _ := it.Peek()
+	assert.EqualString(t, "b", k, "it.Peek()")
+	assert.Equal(t, false, it.Seek("x"), "it.Seek(x)")
+}
@@ -0,0 +1,136 @@
+package immutable
+
+import (
+	"fmt"
+	"strings"
+)
+
+// cell is a cell of a singly linked list. Cells are never modified after
+// they have been created, so lists share their tails.
+type cell[T any] struct {
+	head T
+	tail *cell[T]
+	len  int
+}
+
+func (c *cell[T]) foreach(f func(T) bool) bool {
+	for ; c != nil; c = c.tail {
+		if !f(c.head) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *cell[T]) string() string {
+	var b strings.Builder
+	b.WriteByte('[')
+	f := "%+v"
+	c.foreach(func(v T) bool {
+		_, err := fmt.Fprintf(&b, f, v)
+		f = ", %+v"
+		return err == nil
+	})
+	b.WriteByte(']')
+	return b.String()
+}
+
+// List is a persistent immutable singly linked (cons) list. Push, Pop, Head
+// and Tail take O(1) time and a pushed list shares all values with the list
+// it was pushed onto.
+type List[T any] struct{ cell *cell[T] }
+
+// Len returns the number of values in the list.
+func (a List[T]) Len() int {
+	if a.cell == nil {
+		return 0
+	}
+	return a.cell.len
+}
+
+// Push returns a copy of the list with the value added at the front.
+func (a List[T]) Push(value T) List[T] {
+	return List[T]{&cell[T]{value, a.cell, a.Len() + 1}}
+}
+
+// Head returns the first value of the list along with the value true. It
+// returns (zero, false) when the list is empty.
+func (a List[T]) Head() (T, bool) {
+	if a.cell == nil {
+		var zero T
+		return zero, false
+	}
+	return a.cell.head, true
+}
+
+// Tail returns the list without its first value. The tail of an empty list is
+// the empty list.
+func (a List[T]) Tail() List[T] {
+	if a.cell == nil {
+		return a
+	}
+	return List[T]{a.cell.tail}
+}
+
+// Pop returns the first value and the list without that value along with the
+// value true. It returns (zero, a, false) when the list is empty.
+func (a List[T]) Pop() (T, List[T], bool) {
+	v, ok := a.Head()
+	return v, a.Tail(), ok
+}
+
+// Reverse returns a list with the values in reverse order.
+func (a List[T]) Reverse() List[T] {
+	var r List[T]
+	a.cell.foreach(func(v T) bool {
+		r = r.Push(v)
+		return true
+	})
+	return r
+}
+
+// Range calls the given function for every value from front to back.
+func (a List[T]) Range(f func(T) bool) {
+	a.cell.foreach(f)
+}
+
+// String returns a string representation of the values in the list.
+func (a List[T]) String() string {
+	return a.cell.string()
+}
+
+// Stack is a persistent immutable last-in-first-out stack.
+type Stack[T any] struct{ list List[T] }
+
+// Len returns the number of values on the stack.
+func (a Stack[T]) Len() int {
+	return a.list.Len()
+}
+
+// Push returns a copy of the stack with the value on top.
+func (a Stack[T]) Push(value T) Stack[T] {
+	return Stack[T]{a.list.Push(value)}
+}
+
+// Peek returns the value on top of the stack along with the value true. It
+// returns (zero, false) when the stack is empty.
+func (a Stack[T]) Peek() (T, bool) {
+	return a.list.Head()
+}
+
+// Pop returns the value on top and the stack without that value along with
+// the value true. It returns (zero, a, false) when the stack is empty.
+func (a Stack[T]) Pop() (T, Stack[T], bool) {
+	v, list, ok := a.list.Pop()
+	return v, Stack[T]{list}, ok
+}
+
+// Range calls the given function for every value from top to bottom.
+func (a Stack[T]) Range(f func(T) bool) {
+	a.list.Range(f)
+}
+
+// String returns a string representation of the values from top to bottom.
+func (a Stack[T]) String() string {
+	return a.list.String()
+}
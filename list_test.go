@@ -0,0 +1,49 @@
+package immutable
+
+import (
+	"testing"
+)
+
+func TestList(t *testing.T) {
+	var l0 List[int]
+	l1 := l0.Push(3).Push(2).Push(1)
+	l2 := l1.Tail().Push(20)
+
+	assert.EqualInt(t, 0, l0.Len(), "l0.Len()")
+	assert.EqualInt(t, 3, l1.Len(), "l1.Len()")
+	assert.EqualString(t, "[]", l0.String(), "l0.String()")
+	assert.EqualString(t, "[1, 2, 3]", l1.String(), "l1.String()")
+	assert.EqualString(t, "[20, 2, 3]", l2.String(), "l2.String()")
+	assert.EqualString(t, "[3, 2, 1]", l1.Reverse().String(), "l1.Reverse().String()")
+	assert.Equal(t, l1.cell.tail, l2.cell.tail, "l1 and l2 share their tail")
+
+	v, ok := l1.Head()
+	assert.Equal(t, true, ok, "l1.Head()")
+	assert.EqualInt(t, 1, v, "l1.Head()")
+	_, ok = l0.Head()
+	assert.Equal(t, false, ok, "l0.Head()")
+	assert.EqualInt(t, 0, l0.Tail().Len(), "l0.Tail().Len()")
+
+	v, l3, ok := l2.Pop()
+	assert.Equal(t, true, ok, "l2.Pop()")
+	assert.EqualInt(t, 20, v, "l2.Pop()")
+	assert.EqualString(t, "[2, 3]", l3.String(), "l3.String()")
+}
+
+func TestStack(t *testing.T) {
+	var s Stack[string]
+	s = s.Push("a").Push("b")
+
+	v, ok := s.Peek()
+	assert.Equal(t, true, ok, "s.Peek()")
+	assert.EqualString(t, "b", v, "s.Peek()")
+	assert.EqualString(t, "[b, a]", s.String(), "s.String()")
+
+	v, s1, _ := s.Pop()
+	assert.EqualString(t, "b", v, "s.Pop()")
+	_, s2, _ := s1.Pop()
+	_, s3, ok := s2.Pop()
+	assert.Equal(t, false, ok, "s2.Pop()")
+	assert.EqualInt(t, 0, s3.Len(), "s3.Len()")
+	assert.EqualInt(t, 2, s.Len(), "s.Len()")
+}
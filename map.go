@@ -20,19 +20,19 @@ func (a Map[K, V]) Depth() int {
 // Lookup returns the value of an entry associated with a given key along with
 // the value true when the key is present. Otherwise it returns (zero, false).
 func (a Map[K, V]) Lookup(key K) (V, bool) {
-	return a.lookup(hash(key), 0, key)
+	return a.lookup(hash(key), 0, key, equals[K])
 }
 
 // Has returns true when an entry with the given key is present.
 func (a Map[K, V]) Has(key K) bool {
-	_, b := a.lookup(hash(key), 0, key)
+	_, b := a.lookup(hash(key), 0, key, equals[K])
 	return b
 }
 
 // Get returns the value for the entry with the given key or zero value
 // when it is not present.
 func (a Map[K, V]) Get(key K) V {
-	v, _ := a.lookup(hash(key), 0, key)
+	v, _ := a.lookup(hash(key), 0, key, equals[K])
 	return v
 }
 
@@ -48,12 +48,12 @@ func (a Map[K, V]) String() string {
 
 // Set returns a copy of the Map with the given key,value pair inserted.
 func (a Map[K, V]) Set(key K, value V) Map[K, V] {
-	return Map[K, V]{a.set(hash(key), 0, key, value)}
+	return Map[K, V]{a.set(hash(key), 0, key, value, equals[K])}
 }
 
 // Del returns a copy of the Map with the entry for the key removed.
 func (a Map[K, V]) Del(key K) Map[K, V] {
-	return Map[K, V]{a.delete(hash(key), 0, key)}
+	return Map[K, V]{a.delete(hash(key), 0, key, equals[K])}
 }
 
 // MapX is a persistent immutable hash array mapped trie (HAMT) with an
@@ -88,7 +88,7 @@ func (a MapX[K, V]) Lookup(key K) (V, bool) {
 	if e != nil {
 		panic(UnhashableKeyType)
 	}
-	return a.lookup(hash(k), 0, key)
+	return a.lookup(hash(k), 0, key, equals[K])
 }
 
 // Has returns true when an entry with the given key is present.
@@ -97,7 +97,7 @@ func (a MapX[K, V]) Has(key K) bool {
 	if e != nil {
 		panic(UnhashableKeyType)
 	}
-	_, b := a.lookup(hash(k), 0, key)
+	_, b := a.lookup(hash(k), 0, key, equals[K])
 	return b
 }
 
@@ -108,7 +108,7 @@ func (a MapX[K, V]) Get(key K) V {
 	if e != nil {
 		panic(UnhashableKeyType)
 	}
-	v, _ := a.lookup(hash(k), 0, key)
+	v, _ := a.lookup(hash(k), 0, key, equals[K])
 	return v
 }
 
@@ -128,7 +128,7 @@ func (a MapX[K, V]) Set(key K, value V) MapX[K, V] {
 	if e != nil {
 		panic(UnhashableKeyType)
 	}
-	return MapX[K, V]{a.set(hash(k), 0, key, value), a.marshal}
+	return MapX[K, V]{a.set(hash(k), 0, key, value, equals[K]), a.marshal}
 }
 
 // Del returns a copy of the Map with the entry for the key removed.
@@ -137,5 +137,78 @@ func (a MapX[K, V]) Del(key K) MapX[K, V] {
 	if e != nil {
 		panic(UnhashableKeyType)
 	}
-	return MapX[K, V]{a.delete(hash(k), 0, key), a.marshal}
+	return MapX[K, V]{a.delete(hash(k), 0, key, equals[K]), a.marshal}
+}
+
+// MapH is a persistent immutable hash array mapped trie (HAMT) with an
+// external hash function and an external equal function. Because keys are
+// never compared using the '==' operator, the key type can be any type,
+// including slices and structs holding slices. The hash function must return
+// the same hash for keys that are equal according to the equal function.
+// The 64 bit hash is folded into the 32 bit prefix used by the trie, keys
+// with the same prefix are stored in the collision level and told apart by
+// the equal function.
+type MapH[K, V any] struct {
+	amt[K, V]
+	hash  func(K) uint64
+	equal func(K, K) bool
+}
+
+func MapWithHasher[K, V any](hash func(K) uint64, equal func(K, K) bool) MapH[K, V] {
+	return MapH[K, V]{amt[K, V]{}, hash, equal}
+}
+
+func (a MapH[K, V]) prefix(key K) uint32 {
+	h := a.hash(key)
+	return uint32(h) ^ uint32(h>>32)
+}
+
+// Len returns the number of entries that are present.
+func (a MapH[K, V]) Len() int {
+	return a.len()
+}
+
+// Depth returns the number of levels in the Map.
+// Calling Depth on an empty amt returns 1.
+func (a MapH[K, V]) Depth() int {
+	return a.depth()
+}
+
+// Lookup returns the value of an entry associated with a given key along with
+// the value true when the key is present. Otherwise it returns (zero, false).
+func (a MapH[K, V]) Lookup(key K) (V, bool) {
+	return a.lookup(a.prefix(key), 0, key, a.equal)
+}
+
+// Has returns true when an entry with the given key is present.
+func (a MapH[K, V]) Has(key K) bool {
+	_, b := a.lookup(a.prefix(key), 0, key, a.equal)
+	return b
+}
+
+// Get returns the value for the entry with the given key or zero value
+// when it is not present.
+func (a MapH[K, V]) Get(key K) V {
+	v, _ := a.lookup(a.prefix(key), 0, key, a.equal)
+	return v
+}
+
+// Range calls the given function for every key,value pair present.
+func (a MapH[K, V]) Range(f func(K, V) bool) {
+	a.foreach(f)
+}
+
+// String returns a string representation of the key,value pairs present.
+func (a MapH[K, V]) String() string {
+	return a.string()
+}
+
+// Set returns a copy of the Map with the given key,value pair inserted.
+func (a MapH[K, V]) Set(key K, value V) MapH[K, V] {
+	return MapH[K, V]{a.set(a.prefix(key), 0, key, value, a.equal), a.hash, a.equal}
+}
+
+// Del returns a copy of the Map with the entry for the key removed.
+func (a MapH[K, V]) Del(key K) MapH[K, V] {
+	return MapH[K, V]{a.delete(a.prefix(key), 0, key, a.equal), a.hash, a.equal}
 }
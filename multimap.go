@@ -0,0 +1,91 @@
+package immutable
+
+// MultiMap is a persistent immutable map from keys to sets of values. It is
+// built on a Map holding a Set of values for every key. A key is only present
+// while its set of values is not empty.
+type MultiMap[K, V comparable] struct {
+	sets Map[K, Set[V]]
+	len  int
+}
+
+// Len returns the total number of key,value pairs that are present.
+func (a MultiMap[K, V]) Len() int {
+	return a.len
+}
+
+// KeyLen returns the number of distinct keys that are present.
+func (a MultiMap[K, V]) KeyLen() int {
+	return a.sets.Len()
+}
+
+// Get returns the set of values associated with the given key. The set is
+// empty when the key is not present.
+func (a MultiMap[K, V]) Get(key K) Set[V] {
+	return a.sets.Get(key)
+}
+
+// Has returns true when the given key,value pair is present.
+func (a MultiMap[K, V]) Has(key K, value V) bool {
+	return a.sets.Get(key).Has(value)
+}
+
+// HasKey returns true when at least one value is associated with the key.
+func (a MultiMap[K, V]) HasKey(key K) bool {
+	return a.sets.Has(key)
+}
+
+// Range calls the given function for every key,value pair present.
+func (a MultiMap[K, V]) Range(f func(K, V) bool) {
+	a.sets.Range(func(key K, values Set[V]) bool {
+		more := true
+		values.Range(func(value V) bool {
+			more = f(key, value)
+			return more
+		})
+		return more
+	})
+}
+
+// RangeSets calls the given function for every key along with its set of
+// values.
+func (a MultiMap[K, V]) RangeSets(f func(K, Set[V]) bool) {
+	a.sets.Range(f)
+}
+
+// String returns a string representation of the keys and their sets of
+// values.
+func (a MultiMap[K, V]) String() string {
+	return a.sets.String()
+}
+
+// Add returns a copy of the MultiMap with the key,value pair added.
+func (a MultiMap[K, V]) Add(key K, value V) MultiMap[K, V] {
+	values := a.sets.Get(key)
+	if values.Has(value) {
+		return a
+	}
+	return MultiMap[K, V]{a.sets.Set(key, values.Put(value)), a.len + 1}
+}
+
+// Remove returns a copy of the MultiMap with the key,value pair removed. The
+// key is removed when no values remain associated with it.
+func (a MultiMap[K, V]) Remove(key K, value V) MultiMap[K, V] {
+	values := a.sets.Get(key)
+	if !values.Has(value) {
+		return a
+	}
+	if values = values.Del(value); values.Len() == 0 {
+		return MultiMap[K, V]{a.sets.Del(key), a.len - 1}
+	}
+	return MultiMap[K, V]{a.sets.Set(key, values), a.len - 1}
+}
+
+// RemoveAll returns a copy of the MultiMap with the key and all its values
+// removed.
+func (a MultiMap[K, V]) RemoveAll(key K) MultiMap[K, V] {
+	values, ok := a.sets.Lookup(key)
+	if !ok {
+		return a
+	}
+	return MultiMap[K, V]{a.sets.Del(key), a.len - values.Len()}
+}
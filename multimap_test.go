@@ -0,0 +1,41 @@
+package immutable
+
+import (
+	"testing"
+)
+
+func TestMultiMap(t *testing.T) {
+	var m0 MultiMap[string, int]
+	m1 := m0.Add("a", 1).Add("a", 2).Add("b", 3).Add("a", 1)
+
+	assert.EqualInt(t, 3, m1.Len(), "m1.Len()")
+	assert.EqualInt(t, 2, m1.KeyLen(), "m1.KeyLen()")
+	assert.Equal(t, true, m1.Has("a", 2), "m1.Has(a, 2)")
+	assert.Equal(t, false, m1.Has("b", 2), "m1.Has(b, 2)")
+	assert.EqualInt(t, 2, m1.Get("a").Len(), "m1.Get(a).Len()")
+	assert.EqualInt(t, 0, m1.Get("c").Len(), "m1.Get(c).Len()")
+	assert.EqualString(t, "{b:{3}}", m0.Add("b", 3).String(), "m0.Add(b, 3).String()")
+
+	m2 := m1.Remove("b", 3).Remove("a", 7)
+	assert.EqualInt(t, 2, m2.Len(), "m2.Len()")
+	assert.Equal(t, false, m2.HasKey("b"), "m2.HasKey(b)")
+	assert.Equal(t, true, m1.HasKey("b"), "m1.HasKey(b)")
+
+	m3 := m1.RemoveAll("a")
+	assert.EqualInt(t, 1, m3.Len(), "m3.Len()")
+	assert.EqualInt(t, 1, m3.KeyLen(), "m3.KeyLen()")
+
+	sum := 0
+	m1.Range(func(k string, v int) bool {
+		sum += v
+		return true
+	})
+	assert.EqualInt(t, 6, sum, "m1.Range()")
+
+	count := 0
+	m1.Range(func(string, int) bool {
+		count++
+		return false
+	})
+	assert.EqualInt(t, 1, count, "m1.Range() stop")
+}
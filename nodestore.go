@@ -0,0 +1,117 @@
+package immutable
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"sync"
+)
+
+// NodeStore is an append-only store for serialized trie nodes. Append returns
+// the offset at which the data was stored and Read returns the data that was
+// appended at that offset. Valid offsets are never zero, the zero offset is
+// used to refer to an empty trie.
+type NodeStore interface {
+	Append(data []byte) (int64, error)
+	Read(offset int64) ([]byte, error)
+}
+
+// FileNodeStore is a NodeStore that appends nodes to a local file. Every node
+// is written as a 4 byte little endian length followed by the node data.
+// Data that was appended is never modified, so every offset ever returned
+// by Append remains readable for as long as the file exists.
+type FileNodeStore struct {
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+var fileNodeStoreMagic = []byte("immutbl\n")
+
+// OpenFileNodeStore opens the file with the given name for appending nodes.
+// The file is created when it does not exist yet.
+func OpenFileNodeStore(name string) (*FileNodeStore, error) {
+	file, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	size := info.Size()
+	if size == 0 {
+		if _, err := file.WriteAt(fileNodeStoreMagic, 0); err != nil {
+			file.Close()
+			return nil, err
+		}
+		size = int64(len(fileNodeStoreMagic))
+	} else {
+		magic := make([]byte, len(fileNodeStoreMagic))
+		if _, err := file.ReadAt(magic, 0); err != nil || !bytes.Equal(magic, fileNodeStoreMagic) {
+			file.Close()
+			return nil, InvalidNodeStore
+		}
+	}
+	return &FileNodeStore{file: file, size: size}, nil
+}
+
+// Append writes the data at the end of the file and returns its offset.
+func (s *FileNodeStore) Append(data []byte) (int64, error) {
+	buf := make([]byte, 4+len(data))
+	binary.LittleEndian.PutUint32(buf, uint32(len(data)))
+	copy(buf[4:], data)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.file.WriteAt(buf, s.size); err != nil {
+		return 0, err
+	}
+	offset := s.size
+	s.size += int64(len(buf))
+	return offset, nil
+}
+
+// Read returns the data that was appended at the given offset.
+func (s *FileNodeStore) Read(offset int64) ([]byte, error) {
+	if offset < int64(len(fileNodeStoreMagic)) {
+		return nil, InvalidNodeOffset
+	}
+	s.mu.Lock()
+	size := s.size
+	s.mu.Unlock()
+	if offset+4 > size {
+		return nil, InvalidNodeOffset
+	}
+	var head [4]byte
+	if _, err := s.file.ReadAt(head[:], offset); err != nil {
+		if err == io.EOF {
+			return nil, InvalidNodeOffset
+		}
+		return nil, err
+	}
+	// check the length before allocating, a wrong offset reads garbage
+	n := int64(binary.LittleEndian.Uint32(head[:]))
+	if offset+4+n > size {
+		return nil, InvalidNodeOffset
+	}
+	data := make([]byte, n)
+	if _, err := s.file.ReadAt(data, offset+4); err != nil {
+		if err == io.EOF {
+			return nil, InvalidNodeOffset
+		}
+		return nil, err
+	}
+	return data, nil
+}
+
+// Sync commits the appended nodes to stable storage.
+func (s *FileNodeStore) Sync() error {
+	return s.file.Sync()
+}
+
+// Close closes the underlying file.
+func (s *FileNodeStore) Close() error {
+	return s.file.Close()
+}
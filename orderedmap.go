@@ -0,0 +1,147 @@
+package immutable
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OrderedMap is a persistent immutable map that keeps its entries sorted by
+// key. It is implemented as a weight balanced binary search tree with path
+// copying, so every modification shares all untouched nodes with the
+// original. Keys are ordered by an external compare function that returns a
+// negative number when a < b, a positive number when a > b and zero when a
+// and b are equal, e.g. strings.Compare.
+type OrderedMap[K, V any] struct {
+	root    *node[K, V]
+	compare func(K, K) int
+}
+
+func OrderedMapWith[K, V any](compare func(K, K) int) OrderedMap[K, V] {
+	return OrderedMap[K, V]{nil, compare}
+}
+
+// Len returns the number of entries that are present.
+func (a OrderedMap[K, V]) Len() int {
+	return a.root.len()
+}
+
+// Depth returns the number of levels in the tree.
+// Calling Depth on an empty tree returns 0.
+func (a OrderedMap[K, V]) Depth() int {
+	return a.root.depth()
+}
+
+// Lookup returns the value of an entry associated with a given key along with
+// the value true when the key is present. Otherwise it returns (zero, false).
+func (a OrderedMap[K, V]) Lookup(key K) (V, bool) {
+	return entryOf(a.root.lookup(key, a.compare))
+}
+
+// Has returns true when an entry with the given key is present.
+func (a OrderedMap[K, V]) Has(key K) bool {
+	return a.root.lookup(key, a.compare) != nil
+}
+
+// Get returns the value for the entry with the given key or zero value
+// when it is not present.
+func (a OrderedMap[K, V]) Get(key K) V {
+	v, _ := entryOf(a.root.lookup(key, a.compare))
+	return v
+}
+
+// Range calls the given function for every key,value pair present in
+// ascending key order.
+func (a OrderedMap[K, V]) Range(f func(K, V) bool) {
+	a.root.foreach(f)
+}
+
+// RangeReverse calls the given function for every key,value pair present in
+// descending key order.
+func (a OrderedMap[K, V]) RangeReverse(f func(K, V) bool) {
+	a.root.reverse(f)
+}
+
+// RangeFrom calls the given function in ascending key order for every
+// key,value pair with a key in the half open range [lo, hi).
+func (a OrderedMap[K, V]) RangeFrom(lo, hi K, f func(K, V) bool) {
+	a.root.between(lo, hi, a.compare, f)
+}
+
+// String returns a string representation of the key,value pairs present.
+func (a OrderedMap[K, V]) String() string {
+	var b strings.Builder
+	b.WriteByte('{')
+	f := "%+v:%+v"
+	a.root.foreach(func(k K, v V) bool {
+		_, err := fmt.Fprintf(&b, f, k, v)
+		f = ", %+v:%+v"
+		return err == nil
+	})
+	b.WriteByte('}')
+	return b.String()
+}
+
+// Set returns a copy of the OrderedMap with the given key,value pair inserted.
+func (a OrderedMap[K, V]) Set(key K, value V) OrderedMap[K, V] {
+	return OrderedMap[K, V]{a.root.insert(key, value, a.compare), a.compare}
+}
+
+// Del returns a copy of the OrderedMap with the entry for the key removed.
+func (a OrderedMap[K, V]) Del(key K) OrderedMap[K, V] {
+	return OrderedMap[K, V]{a.root.delete(key, a.compare), a.compare}
+}
+
+// Min returns the entry with the smallest key along with the value true.
+// When the map is empty it returns (zero, zero, false).
+func (a OrderedMap[K, V]) Min() (K, V, bool) {
+	return keyEntryOf(a.root.min())
+}
+
+// Max returns the entry with the largest key along with the value true.
+// When the map is empty it returns (zero, zero, false).
+func (a OrderedMap[K, V]) Max() (K, V, bool) {
+	return keyEntryOf(a.root.max())
+}
+
+// Floor returns the entry with the largest key smaller than or equal to the
+// given key along with the value true. It returns (zero, zero, false) when
+// there is no such entry.
+func (a OrderedMap[K, V]) Floor(key K) (K, V, bool) {
+	return keyEntryOf(a.root.floor(key, true, a.compare))
+}
+
+// Ceiling returns the entry with the smallest key larger than or equal to the
+// given key along with the value true. It returns (zero, zero, false) when
+// there is no such entry.
+func (a OrderedMap[K, V]) Ceiling(key K) (K, V, bool) {
+	return keyEntryOf(a.root.ceiling(key, true, a.compare))
+}
+
+// Rank returns the number of keys in the map that are smaller than the given
+// key. When the key is present this is its index in key order.
+func (a OrderedMap[K, V]) Rank(key K) int {
+	return a.root.rank(key, a.compare)
+}
+
+// Select returns the entry at the given index in key order along with the
+// value true. It returns (zero, zero, false) when the index is out of range.
+func (a OrderedMap[K, V]) Select(index int) (K, V, bool) {
+	return keyEntryOf(a.root.at(index))
+}
+
+func entryOf[K, V any](n *node[K, V]) (V, bool) {
+	if n == nil {
+		var zero V
+		return zero, false
+	}
+	return n.value, true
+}
+
+func keyEntryOf[K, V any](n *node[K, V]) (K, V, bool) {
+	if n == nil {
+		var key K
+		var value V
+		return key, value, false
+	}
+	return n.key, n.value, true
+}
@@ -0,0 +1,133 @@
+package immutable
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// balanced returns true when every node in the tree is weight balanced and
+// has the correct size.
+func balanced[K, V any](n *node[K, V]) bool {
+	if n == nil {
+		return true
+	}
+	l, r := n.left.len()+1, n.right.len()+1
+	return n.size == l+r-1 && l <= delta*r && r <= delta*l && balanced(n.left) && balanced(n.right)
+}
+
+func TestOrderedMap(t *testing.T) {
+	m := OrderedMapWith[int, int](compareInt)
+	ref := map[int]int{}
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		k := rnd.Intn(500)
+		if rnd.Intn(3) == 0 {
+			m = m.Del(k)
+			delete(ref, k)
+		} else {
+			m = m.Set(k, i)
+			ref[k] = i
+		}
+	}
+	assert.Equal(t, true, balanced(m.root), "balanced(m.root)")
+	assert.EqualInt(t, len(ref), m.Len(), "m.Len()")
+
+	keys := make([]int, 0, len(ref))
+	for k := range ref {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+
+	i := 0
+	m.Range(func(k, v int) bool {
+		assert.EqualInt(t, keys[i], k, "m.Range() key #%d", i)
+		assert.EqualInt(t, ref[k], v, "m.Range() value #%d", i)
+		i++
+		return true
+	})
+	assert.EqualInt(t, len(keys), i, "m.Range() count")
+
+	for i, k := range keys {
+		assert.EqualInt(t, i, m.Rank(k), "m.Rank(%d)", k)
+		s, v, ok := m.Select(i)
+		assert.Equal(t, true, ok, "m.Select(%d)", i)
+		assert.EqualInt(t, k, s, "m.Select(%d)", i)
+		assert.EqualInt(t, ref[k], v, "m.Select(%d)", i)
+	}
+	_, _, ok := m.Select(len(keys))
+	assert.Equal(t, false, ok, "m.Select(len)")
+
+	min, _, _ := m.Min()
+	max, _, _ := m.Max()
+	assert.EqualInt(t, keys[0], min, "m.Min()")
+	assert.EqualInt(t, keys[len(keys)-1], max, "m.Max()")
+
+	for k := -1; k <= 501; k++ {
+		j := sort.SearchInts(keys, k)
+		c, _, ok := m.Ceiling(k)
+		assert.Equal(t, j < len(keys), ok, "m.Ceiling(%d)", k)
+		if ok {
+			assert.EqualInt(t, keys[j], c, "m.Ceiling(%d)", k)
+		}
+		if j == len(keys) || keys[j] != k {
+			j--
+		}
+		f, _, ok := m.Floor(k)
+		assert.Equal(t, j >= 0, ok, "m.Floor(%d)", k)
+		if ok {
+			assert.EqualInt(t, keys[j], f, "m.Floor(%d)", k)
+		}
+	}
+
+	var got []int
+	m.RangeFrom(100, 200, func(k, v int) bool {
+		got = append(got, k)
+		return true
+	})
+	lo, hi := sort.SearchInts(keys, 100), sort.SearchInts(keys, 200)
+	assert.EqualInt(t, hi-lo, len(got), "m.RangeFrom(100, 200)")
+	for i, k := range got {
+		assert.EqualInt(t, keys[lo+i], k, "m.RangeFrom(100, 200) #%d", i)
+	}
+}
+
+func TestOrderedMapPersistent(t *testing.T) {
+	m0 := OrderedMapWith[string, int](func(a, b string) int {
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+		return 0
+	})
+	m1 := m0.Set("b", 2).Set("a", 1).Set("c", 3)
+	m2 := m1.Set("b", 20).Del("a")
+
+	assert.EqualString(t, "{}", m0.String(), "m0.String()")
+	assert.EqualString(t, "{a:1, b:2, c:3}", m1.String(), "m1.String()")
+	assert.EqualString(t, "{b:20, c:3}", m2.String(), "m2.String()")
+	assert.Equal(t, true, m1.Has("a"), "m1.Has(a)")
+	assert.Equal(t, false, m2.Has("a"), "m2.Has(a)")
+	assert.Equal(t, m1.root, m1.Del("x").root, "m1.Del(x).root")
+
+	var keys []string
+	m1.RangeReverse(func(k string, _ int) bool {
+		keys = append(keys, k)
+		return len(keys) < 2
+	})
+	assert.EqualInt(t, 2, len(keys), "m1.RangeReverse()")
+	assert.EqualString(t, "c", keys[0], "m1.RangeReverse() #0")
+	assert.EqualString(t, "b", keys[1], "m1.RangeReverse() #1")
+}
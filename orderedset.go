@@ -0,0 +1,139 @@
+package immutable
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OrderedSet is a persistent immutable set that keeps its keys sorted. It is
+// the ordered counterpart of Set and uses the same weight balanced tree as
+// OrderedMap. Keys are ordered by an external compare function.
+type OrderedSet[K any] struct {
+	root    *node[K, struct{}]
+	compare func(K, K) int
+}
+
+func OrderedSetWith[K any](compare func(K, K) int) OrderedSet[K] {
+	return OrderedSet[K]{nil, compare}
+}
+
+// Len returns the number of keys that are present.
+func (a OrderedSet[K]) Len() int {
+	return a.root.len()
+}
+
+// Depth returns the number of levels in the tree.
+// Calling Depth on an empty tree returns 0.
+func (a OrderedSet[K]) Depth() int {
+	return a.root.depth()
+}
+
+// Has returns true when the given key is present.
+func (a OrderedSet[K]) Has(key K) bool {
+	return a.root.lookup(key, a.compare) != nil
+}
+
+// Range calls the given function for every key present in ascending order.
+func (a OrderedSet[K]) Range(f func(K) bool) {
+	a.root.foreach(func(key K, _ struct{}) bool { return f(key) })
+}
+
+// RangeReverse calls the given function for every key present in descending
+// order.
+func (a OrderedSet[K]) RangeReverse(f func(K) bool) {
+	a.root.reverse(func(key K, _ struct{}) bool { return f(key) })
+}
+
+// RangeFrom calls the given function in ascending order for every key in the
+// half open range [lo, hi).
+func (a OrderedSet[K]) RangeFrom(lo, hi K, f func(K) bool) {
+	a.root.between(lo, hi, a.compare, func(key K, _ struct{}) bool { return f(key) })
+}
+
+// String returns a string representation of the keys present.
+func (a OrderedSet[K]) String() string {
+	var b strings.Builder
+	b.WriteByte('{')
+	f := "%+v"
+	a.root.foreach(func(k K, _ struct{}) bool {
+		_, err := fmt.Fprintf(&b, f, k)
+		f = ", %+v"
+		return err == nil
+	})
+	b.WriteByte('}')
+	return b.String()
+}
+
+// Put returns a copy of the OrderedSet with the key added to it.
+func (a OrderedSet[K]) Put(key K) OrderedSet[K] {
+	return OrderedSet[K]{a.root.insert(key, struct{}{}, a.compare), a.compare}
+}
+
+// Del returns a copy of the OrderedSet with the key removed from it.
+func (a OrderedSet[K]) Del(key K) OrderedSet[K] {
+	return OrderedSet[K]{a.root.delete(key, a.compare), a.compare}
+}
+
+// Min returns the smallest key along with the value true. When the set is
+// empty it returns (zero, false).
+func (a OrderedSet[K]) Min() (K, bool) {
+	return keyOf(a.root.min())
+}
+
+// Max returns the largest key along with the value true. When the set is
+// empty it returns (zero, false).
+func (a OrderedSet[K]) Max() (K, bool) {
+	return keyOf(a.root.max())
+}
+
+// Floor returns the largest key smaller than or equal to the given key along
+// with the value true. It returns (zero, false) when there is no such key.
+func (a OrderedSet[K]) Floor(key K) (K, bool) {
+	return keyOf(a.root.floor(key, true, a.compare))
+}
+
+// Ceiling returns the smallest key larger than or equal to the given key
+// along with the value true. It returns (zero, false) when there is no such
+// key.
+func (a OrderedSet[K]) Ceiling(key K) (K, bool) {
+	return keyOf(a.root.ceiling(key, true, a.compare))
+}
+
+// Predecessor returns the largest key strictly smaller than the given key
+// along with the value true. It returns (zero, false) when there is no such
+// key.
+func (a OrderedSet[K]) Predecessor(key K) (K, bool) {
+	return keyOf(a.root.floor(key, false, a.compare))
+}
+
+// Successor returns the smallest key strictly larger than the given key
+// along with the value true. It returns (zero, false) when there is no such
+// key.
+func (a OrderedSet[K]) Successor(key K) (K, bool) {
+	return keyOf(a.root.ceiling(key, false, a.compare))
+}
+
+// Union returns a set with the keys present in either set. The sets are
+// combined by splitting on key order, which takes at most linear time and
+// reuses whole subtrees of both sets.
+func (a OrderedSet[K]) Union(b OrderedSet[K]) OrderedSet[K] {
+	return OrderedSet[K]{a.root.union(b.root, a.compare), a.compare}
+}
+
+// Intersection returns a set with the keys present in both sets.
+func (a OrderedSet[K]) Intersection(b OrderedSet[K]) OrderedSet[K] {
+	return OrderedSet[K]{a.root.intersection(b.root, a.compare), a.compare}
+}
+
+// Difference returns a set with the keys of a that are not present in b.
+func (a OrderedSet[K]) Difference(b OrderedSet[K]) OrderedSet[K] {
+	return OrderedSet[K]{a.root.difference(b.root, a.compare), a.compare}
+}
+
+func keyOf[K, V any](n *node[K, V]) (K, bool) {
+	if n == nil {
+		var zero K
+		return zero, false
+	}
+	return n.key, true
+}
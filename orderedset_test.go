@@ -0,0 +1,89 @@
+package immutable
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestOrderedSet(t *testing.T) {
+	s := OrderedSetWith[int](compareInt).Put(30).Put(10).Put(20).Put(40)
+
+	assert.EqualString(t, "{10, 20, 30, 40}", s.String(), "s.String()")
+	assert.Equal(t, true, s.Has(20), "s.Has(20)")
+	assert.Equal(t, false, s.Del(20).Has(20), "s.Del(20).Has(20)")
+	assert.Equal(t, true, s.Has(20), "s.Has(20)")
+
+	tests := []struct {
+		name     string
+		f        func(int) (int, bool)
+		key, exp int
+		ok       bool
+	}{
+		{"Floor", s.Floor, 20, 20, true},
+		{"Floor", s.Floor, 25, 20, true},
+		{"Floor", s.Floor, 5, 0, false},
+		{"Ceiling", s.Ceiling, 20, 20, true},
+		{"Ceiling", s.Ceiling, 25, 30, true},
+		{"Ceiling", s.Ceiling, 45, 0, false},
+		{"Predecessor", s.Predecessor, 20, 10, true},
+		{"Predecessor", s.Predecessor, 10, 0, false},
+		{"Successor", s.Successor, 20, 30, true},
+		{"Successor", s.Successor, 40, 0, false},
+	}
+	for _, test := range tests {
+		got, ok := test.f(test.key)
+		assert.Equal(t, test.ok, ok, "s.%s(%d)", test.name, test.key)
+		assert.EqualInt(t, test.exp, got, "s.%s(%d)", test.name, test.key)
+	}
+
+	var keys []int
+	s.RangeReverse(func(k int) bool {
+		keys = append(keys, k)
+		return true
+	})
+	assert.EqualInt(t, 4, len(keys), "s.RangeReverse()")
+	assert.EqualInt(t, 40, keys[0], "s.RangeReverse() #0")
+	assert.EqualInt(t, 10, keys[3], "s.RangeReverse() #3")
+}
+
+func TestOrderedSetAlgebra(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	a, b := OrderedSetWith[int](compareInt), OrderedSetWith[int](compareInt)
+	ina, inb := map[int]bool{}, map[int]bool{}
+	for i := 0; i < 300; i++ {
+		k := rnd.Intn(400)
+		a, ina[k] = a.Put(k), true
+		k = rnd.Intn(400)
+		b, inb[k] = b.Put(k), true
+	}
+	union, intersection, difference := a.Union(b), a.Intersection(b), a.Difference(b)
+	assert.Equal(t, true, balanced(union.root), "balanced(union)")
+	assert.Equal(t, true, balanced(intersection.root), "balanced(intersection)")
+	assert.Equal(t, true, balanced(difference.root), "balanced(difference)")
+
+	nu, ni, nd := 0, 0, 0
+	for k := 0; k < 400; k++ {
+		assert.Equal(t, ina[k] || inb[k], union.Has(k), "union.Has(%d)", k)
+		assert.Equal(t, ina[k] && inb[k], intersection.Has(k), "intersection.Has(%d)", k)
+		assert.Equal(t, ina[k] && !inb[k], difference.Has(k), "difference.Has(%d)", k)
+		if ina[k] || inb[k] {
+			nu++
+		}
+		if ina[k] && inb[k] {
+			ni++
+		}
+		if ina[k] && !inb[k] {
+			nd++
+		}
+	}
+	assert.EqualInt(t, nu, union.Len(), "union.Len()")
+	assert.EqualInt(t, ni, intersection.Len(), "intersection.Len()")
+	assert.EqualInt(t, nd, difference.Len(), "difference.Len()")
+
+	prev := -1
+	union.Range(func(k int) bool {
+		assert.Equal(t, true, prev < k, "union.Range() order at %d", k)
+		prev = k
+		return true
+	})
+}
@@ -0,0 +1,281 @@
+package immutable
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// chunk is the number of keys hashed by a worker in one go.
+const chunk = 4096
+
+// parallel calls f for every i in [0, n) on the given number of worker
+// goroutines. When workers is not positive, GOMAXPROCS workers are used.
+// When f panics, the first panic is repeated in the calling goroutine after
+// all workers have stopped.
+func parallel(workers, n int, f func(i int)) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > n {
+		workers = n
+	}
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	var once sync.Once
+	var failure any
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				if e := recover(); e != nil {
+					once.Do(func() { failure = e })
+				}
+			}()
+			for i := int(next.Add(1) - 1); i < n; i = int(next.Add(1) - 1) {
+				f(i)
+			}
+		}()
+	}
+	wg.Wait()
+	if failure != nil {
+		panic(failure)
+	}
+}
+
+// assemble returns the amt holding the root entries of all the parts. The
+// parts must occupy disjoint root slots and be given in ascending order of
+// their slots.
+func assemble[K comparable, V any](parts []amt[K, V]) amt[K, V] {
+	size := 0
+	for _, p := range parts {
+		size += len(p.entries)
+	}
+	n := amt[K, V]{entries: make([]*entry[V], 0, size)}
+	for _, p := range parts {
+		n.bits |= p.bits
+		n.entries = append(n.entries, p.entries...)
+	}
+	return n
+}
+
+// FromSlice returns a Map with the keys associated with the values at the
+// same index. The keys are hashed and sharded by their root slot, after
+// which the subtries of the root are built concurrently on the given number
+// of worker goroutines. When workers is not positive, GOMAXPROCS workers are
+// used. The result is the same Map as setting the key,value pairs one after
+// the other in order, so for a key present more than once the last value is
+// used. FromSlice panics with LengthMismatch when the slices differ in
+// length.
+func FromSlice[K comparable, V any](keys []K, values []V, workers int) Map[K, V] {
+	if len(keys) != len(values) {
+		panic(LengthMismatch)
+	}
+	prefixes := make([]uint32, len(keys))
+	parallel(workers, (len(keys)+chunk-1)/chunk, func(c int) {
+		for i := c * chunk; i < len(keys) && i < (c+1)*chunk; i++ {
+			prefixes[i] = hash(keys[i])
+		}
+	})
+
+	// order the indexes by root slot, keeping insertion order within a slot
+	var offsets [branching + 1]int
+	for _, p := range prefixes {
+		offsets[p&0x1f+1]++
+	}
+	for s := 1; s <= branching; s++ {
+		offsets[s] += offsets[s-1]
+	}
+	order := make([]int, len(keys))
+	next := offsets
+	for i, p := range prefixes {
+		order[next[p&0x1f]] = i
+		next[p&0x1f]++
+	}
+
+	var parts [branching]amt[K, V]
+	parallel(workers, branching, func(s int) {
+		var n amt[K, V]
+		for _, i := range order[offsets[s]:offsets[s+1]] {
+			n = n.set(prefixes[i], 0, keys[i], values[i], equals[K])
+		}
+		parts[s] = n
+	})
+	return Map[K, V]{assemble(parts[:])}
+}
+
+// FromSeqParallel returns a Map with the key,value pairs produced by the
+// sequence. The sequence is consumed on the calling goroutine, after which
+// the Map is built by FromSlice using the given number of workers.
+func FromSeqParallel[K comparable, V any](seq func(yield func(K, V) bool), workers int) Map[K, V] {
+	var keys []K
+	var values []V
+	seq(func(key K, value V) bool {
+		keys = append(keys, key)
+		values = append(values, value)
+		return true
+	})
+	return FromSlice(keys, values, workers)
+}
+
+// parts returns for every root entry of n an amt holding only that entry.
+func (n amt[K, V]) parts() []amt[K, V] {
+	parts := make([]amt[K, V], len(n.entries))
+	bits := n.bits
+	for i, e := range n.entries {
+		bitpos := bits & -bits
+		bits &^= bitpos
+		parts[i] = amt[K, V]{bitpos, []*entry[V]{e}}
+	}
+	return parts
+}
+
+// each calls f for every entry holding a key,value pair in n.
+func (n amt[K, V]) each(f func(*entry[V]) bool) bool {
+	for _, e := range n.entries {
+		if a, ok := e.ref.(amt[K, V]); ok {
+			if !a.each(f) {
+				return false
+			}
+		} else if !f(e) {
+			return false
+		}
+	}
+	return true
+}
+
+// prange calls f for every part on the given number of workers. The function
+// done passed to f returns true when f should stop because the context was
+// cancelled or f returned an error for another part. No new parts are
+// started after that. prange returns the first error returned by f or the
+// error of the context.
+func prange[K comparable, V any](ctx context.Context, workers int, parts []amt[K, V], f func(i int, part amt[K, V], done func() bool) error) error {
+	var failed atomic.Bool
+	var once sync.Once
+	var first error
+	fail := func(err error) {
+		once.Do(func() { first = err })
+		failed.Store(true)
+	}
+	done := func() bool {
+		if failed.Load() {
+			return true
+		}
+		select {
+		case <-ctx.Done():
+			fail(ctx.Err())
+			return true
+		default:
+			return false
+		}
+	}
+	parallel(workers, len(parts), func(i int) {
+		if done() {
+			return
+		}
+		if err := f(i, parts[i], done); err != nil {
+			fail(err)
+		}
+	})
+	return first
+}
+
+// stopped is returned by a part of ParallelRange to stop the other workers
+// when f returned false. It is not returned to the caller.
+const stopped = MapError("Stopped")
+
+// ParallelRange calls the given function for every key,value pair present on
+// the given number of worker goroutines. The subtries below the root of the
+// Map are divided among the workers. When workers is not positive,
+// GOMAXPROCS workers are used. When f returns false or an error, or the
+// context is cancelled, the workers stop calling f as soon as possible. The
+// error of f or the context is returned, stopping because f returned false
+// returns nil. Calls of f that already started on other workers are not
+// interrupted, but no worker starts a new call after it has seen the stop.
+// The function f is called concurrently and must be safe for concurrent use.
+func (a Map[K, V]) ParallelRange(ctx context.Context, workers int, f func(K, V) (bool, error)) error {
+	err := prange(ctx, workers, a.parts(), func(_ int, part amt[K, V], done func() bool) (err error) {
+		part.foreach(func(key K, value V) bool {
+			if done() {
+				return false
+			}
+			var more bool
+			if more, err = f(key, value); err == nil && !more {
+				err = stopped
+			}
+			return err == nil
+		})
+		return err
+	})
+	if err == stopped {
+		return nil
+	}
+	return err
+}
+
+// ParallelFold folds the key,value pairs present in the Map into a single
+// value on the given number of worker goroutines. Every worker starts from
+// init and folds the pairs of a subtrie with fold. The results of the
+// subtries are then combined with combine in the order of the subtries in
+// the root of the Map. So init must be an identity of combine and combine
+// must be associative. When the context is cancelled, ParallelFold stops as
+// soon as possible and returns init along with the error of the context.
+func ParallelFold[K comparable, V, A any](ctx context.Context, m Map[K, V], workers int, init A, fold func(A, K, V) A, combine func(A, A) A) (A, error) {
+	parts := m.parts()
+	results := make([]A, len(parts))
+	err := prange(ctx, workers, parts, func(i int, part amt[K, V], done func() bool) error {
+		acc := init
+		part.foreach(func(key K, value V) bool {
+			if done() {
+				return false
+			}
+			acc = fold(acc, key, value)
+			return true
+		})
+		results[i] = acc
+		return nil
+	})
+	if err != nil {
+		return init, err
+	}
+	acc := init
+	for _, r := range results {
+		acc = combine(acc, r)
+	}
+	return acc, nil
+}
+
+// ParallelFilter returns a Map with the key,value pairs for which keep
+// returns true. The subtries below the root of the Map are filtered on the
+// given number of worker goroutines and subtries in which every pair is kept
+// are shared with the Map. When the context is cancelled, ParallelFilter
+// stops as soon as possible and returns an empty Map along with the error of
+// the context.
+func (a Map[K, V]) ParallelFilter(ctx context.Context, workers int, keep func(K, V) bool) (Map[K, V], error) {
+	parts := a.parts()
+	err := prange(ctx, workers, parts, func(i int, part amt[K, V], done func() bool) error {
+		var n amt[K, V]
+		all := true
+		part.each(func(e *entry[V]) bool {
+			if done() {
+				return false
+			}
+			if keep(refKey[K](e.ref), e.value) {
+				n = n.set(e.prefix, 0, refKey[K](e.ref), e.value, equals[K])
+			} else {
+				all = false
+			}
+			return true
+		})
+		if !all {
+			parts[i] = n
+		}
+		return nil
+	})
+	if err != nil {
+		return Map[K, V]{}, err
+	}
+	return Map[K, V]{assemble(parts)}, nil
+}
@@ -0,0 +1,149 @@
+package immutable
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+func TestFromSlice(t *testing.T) {
+	var keys []int64
+	var values []int
+	var m Map[int64, int]
+	for i := 0; i < 20000; i++ {
+		// keys 1<<32 apart share a prefix and end up on the collision level
+		k := int64(i % 15000 * 7919)
+		if i%3 == 0 {
+			k += 1 << 32
+		}
+		keys = append(keys, k)
+		values = append(values, i)
+		m = m.Set(k, i)
+	}
+	for _, workers := range []int{0, 1, 3, 64} {
+		p := FromSlice(keys, values, workers)
+		assert.EqualInt(t, m.Len(), p.Len(), "FromSlice(%d).Len()", workers)
+		assert.Equal(t, true, reflect.DeepEqual(m.amt, p.amt), "FromSlice(%d) equals sequential", workers)
+	}
+
+	s := FromSeqParallel(func(yield func(string, int) bool) {
+		for _, k := range []string{"a", "b", "c", "a"} {
+			if !yield(k, len(k)) {
+				return
+			}
+		}
+	}, 2)
+	assert.EqualInt(t, 3, s.Len(), "FromSeqParallel().Len()")
+	assert.Equal(t, true, reflect.DeepEqual(Map[string, int]{}.Set("a", 1).Set("b", 1).Set("c", 1).amt, s.amt), "FromSeqParallel() equals sequential")
+
+	assert.EqualInt(t, 0, FromSlice[int, int](nil, nil, 4).Len(), "FromSlice(nil).Len()")
+}
+
+func TestFromSlicePanics(t *testing.T) {
+	defer func() {
+		assert.Equal(t, UnhashableKeyType, recover(), "recover()")
+	}()
+	FromSlice([]float64{1, 2}, []int{1, 2}, 2)
+	assert.Equal(t, false, true, "Unreachable")
+}
+
+func TestParallelRange(t *testing.T) {
+	var m Map[int, int]
+	for i := 0; i < 5000; i++ {
+		m = m.Set(i, i)
+	}
+	var sum atomic.Int64
+	err := m.ParallelRange(context.Background(), 4, func(k, v int) (bool, error) {
+		sum.Add(int64(v))
+		return true, nil
+	})
+	assert.Equal(t, nil, err, "m.ParallelRange()")
+	assert.EqualInt(t, 5000*4999/2, int(sum.Load()), "sum")
+
+	failed := errors.New("failed")
+	var calls atomic.Int64
+	err = m.ParallelRange(context.Background(), 2, func(k, v int) (bool, error) {
+		calls.Add(1)
+		return true, failed
+	})
+	assert.Equal(t, failed, err, "m.ParallelRange() failed")
+	assert.Equal(t, true, calls.Load() <= 2, "calls <= workers")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = m.ParallelRange(ctx, 2, func(k, v int) (bool, error) {
+		t.Error("called after cancel")
+		return true, nil
+	})
+	assert.Equal(t, context.Canceled, err, "m.ParallelRange() cancelled")
+}
+
+func TestParallelRangeStop(t *testing.T) {
+	var m Map[int, int]
+	for i := 0; i < 5000; i++ {
+		m = m.Set(i, i)
+	}
+	// with a single worker f is not called again after returning false
+	calls := 0
+	err := m.ParallelRange(context.Background(), 1, func(k, v int) (bool, error) {
+		calls++
+		return calls < 10, nil
+	})
+	assert.Equal(t, nil, err, "m.ParallelRange() stop")
+	assert.EqualInt(t, 10, calls, "calls")
+
+	// with more workers only calls already in progress finish
+	var count atomic.Int64
+	err = m.ParallelRange(context.Background(), 4, func(k, v int) (bool, error) {
+		count.Add(1)
+		return false, nil
+	})
+	assert.Equal(t, nil, err, "m.ParallelRange() stop")
+	assert.Equal(t, true, count.Load() <= 4, "calls <= workers")
+}
+
+func TestParallelFold(t *testing.T) {
+	var m Map[string, int]
+	for i := 0; i < 1000; i++ {
+		m = m.Set(strconv.Itoa(i), i)
+	}
+	sum, err := ParallelFold(context.Background(), m, 3, 0,
+		func(acc int, _ string, v int) int { return acc + v },
+		func(a, b int) int { return a + b })
+	assert.Equal(t, nil, err, "ParallelFold() err")
+	assert.EqualInt(t, 1000*999/2, sum, "ParallelFold()")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = ParallelFold(ctx, m, 3, 0,
+		func(acc int, _ string, v int) int { return acc + v },
+		func(a, b int) int { return a + b })
+	assert.Equal(t, context.Canceled, err, "ParallelFold() cancelled")
+}
+
+func TestParallelFilter(t *testing.T) {
+	var m, even Map[int64, int]
+	for i := 0; i < 3000; i++ {
+		k := int64(i % 2000)
+		if i%5 == 0 {
+			k += 1 << 32
+		}
+		m = m.Set(k, i)
+	}
+	m.Range(func(k int64, v int) bool {
+		if v%2 == 0 {
+			even = even.Set(k, v)
+		}
+		return true
+	})
+	f, err := m.ParallelFilter(context.Background(), 4, func(k int64, v int) bool { return v%2 == 0 })
+	assert.Equal(t, nil, err, "m.ParallelFilter() err")
+	assert.EqualInt(t, even.Len(), f.Len(), "m.ParallelFilter().Len()")
+	assert.Equal(t, true, reflect.DeepEqual(even.amt, f.amt), "m.ParallelFilter() equals sequential")
+
+	all, _ := m.ParallelFilter(context.Background(), 4, func(int64, int) bool { return true })
+	assert.Equal(t, m.entries[0], all.entries[0], "ParallelFilter(all) shares root entries")
+}
@@ -0,0 +1,118 @@
+package immutable
+
+// GetIn returns the value found by following the path through nested Maps
+// and Vectors along with the value true. A path element is a string key for
+// a Map[string, any] and an int index for a Vector[any]. GetIn returns
+// (nil, false) when the path does not lead to a value.
+func GetIn(m Map[string, any], path ...any) (any, bool) {
+	var node any = m
+	for _, k := range path {
+		switch n := node.(type) {
+		case Map[string, any]:
+			key, ok := k.(string)
+			if !ok {
+				return nil, false
+			}
+			if node, ok = n.Lookup(key); !ok {
+				return nil, false
+			}
+		case Vector[any]:
+			i, ok := k.(int)
+			if !ok || i < 0 || i >= n.Len() {
+				return nil, false
+			}
+			node = n.Get(i)
+		default:
+			return nil, false
+		}
+	}
+	return node, true
+}
+
+// SetIn returns a copy of the Map with the value stored at the end of the
+// path. The path is passed the same way as to GetIn. Only the Maps and Vectors along the path are copied. Missing Maps
+// along the path are created, an index equal to the length of a Vector
+// appends to it. SetIn panics with InvalidPath when the path runs into a
+// value that is neither a Map nor a Vector or uses a key of the wrong type,
+// and with IndexOutOfRange for an index beyond the end of a Vector.
+func SetIn(m Map[string, any], value any, path ...any) Map[string, any] {
+	return UpdateIn(m, func(any, bool) any { return value }, path...)
+}
+
+// UpdateIn returns a copy of the Map with the value at the end of the path
+// replaced by the result of calling f with the current value and whether it
+// was present. UpdateIn walks the path the same way as SetIn.
+func UpdateIn(m Map[string, any], f func(any, bool) any, path ...any) Map[string, any] {
+	result, ok := updateIn(m, true, path, f).(Map[string, any])
+	if !ok {
+		panic(InvalidPath)
+	}
+	return result
+}
+
+func updateIn(node any, present bool, path []any, f func(any, bool) any) any {
+	if len(path) == 0 {
+		return f(node, present)
+	}
+	if node == nil {
+		node = Map[string, any]{}
+	}
+	switch n := node.(type) {
+	case Map[string, any]:
+		key, ok := path[0].(string)
+		if !ok {
+			panic(InvalidPath)
+		}
+		child, ok := n.Lookup(key)
+		return n.Set(key, updateIn(child, ok, path[1:], f))
+	case Vector[any]:
+		i, ok := path[0].(int)
+		if !ok {
+			panic(InvalidPath)
+		}
+		if i == n.Len() {
+			return n.Append(updateIn(nil, false, path[1:], f))
+		}
+		return n.Set(i, updateIn(n.Get(i), true, path[1:], f))
+	}
+	panic(InvalidPath)
+}
+
+// DelIn returns a copy of the Map with the value at the end of the path
+// removed. Removing an element from a Vector moves the elements after it one
+// position to the front. The Map is returned unchanged when the path does not
+// lead to a value. Deleting the empty path returns an empty Map.
+func DelIn(m Map[string, any], path ...any) Map[string, any] {
+	if len(path) == 0 {
+		return Map[string, any]{}
+	}
+	return delIn(m, path).(Map[string, any])
+}
+
+func delIn(node any, path []any) any {
+	switch n := node.(type) {
+	case Map[string, any]:
+		key, ok := path[0].(string)
+		if !ok {
+			return node
+		}
+		if len(path) == 1 {
+			return n.Del(key)
+		}
+		child, ok := n.Lookup(key)
+		if !ok {
+			return node
+		}
+		return n.Set(key, delIn(child, path[1:]))
+	case Vector[any]:
+		i, ok := path[0].(int)
+		if !ok || i < 0 || i >= n.Len() {
+			return node
+		}
+		if len(path) == 1 {
+			return n.Slice(0, i).Concat(n.Slice(i+1, n.Len()))
+		}
+		return n.Set(i, delIn(n.Get(i), path[1:]))
+	}
+	return node
+}
@@ -0,0 +1,80 @@
+package immutable
+
+import (
+	"testing"
+)
+
+func TestPath(t *testing.T) {
+	var m0 Map[string, any]
+	m1 := SetIn(m0, Vector[any]{}.Append(80), "server", "ports")
+	m2 := SetIn(m1, 443, "server", "ports", 1)
+	m3 := SetIn(m2, "www", "server", "name")
+	m4 := UpdateIn(m3, func(v any, ok bool) any {
+		return v.(int) + 8000
+	}, "server", "ports", 0)
+	m5 := DelIn(m4, "server", "ports", 0)
+
+	v, ok := GetIn(m3, "server", "ports", 1)
+	assert.Equal(t, true, ok, "GetIn(m3, server, ports, 1)")
+	assert.Equal(t, 443, v, "GetIn(m3, server, ports, 1)")
+	v, _ = GetIn(m4, "server", "ports", 0)
+	assert.Equal(t, 8080, v, "GetIn(m4, server, ports, 0)")
+	v, _ = GetIn(m3, "server", "ports", 0)
+	assert.Equal(t, 80, v, "GetIn(m3, server, ports, 0)")
+	v, _ = GetIn(m5, "server", "ports", 0)
+	assert.Equal(t, 443, v, "GetIn(m5, server, ports, 0)")
+	v, _ = GetIn(m5, "server", "name")
+	assert.Equal(t, "www", v, "GetIn(m5, server, name)")
+
+	_, ok = GetIn(m1, "server", "name")
+	assert.Equal(t, false, ok, "GetIn(m1, server, name)")
+	_, ok = GetIn(m3, "server", "name", "x")
+	assert.Equal(t, false, ok, "GetIn(m3, server, name, x)")
+	_, ok = GetIn(m3, "server", "ports", "x")
+	assert.Equal(t, false, ok, "GetIn(m3, server, ports, x)")
+	_, ok = GetIn(m0, "server")
+	assert.Equal(t, false, ok, "GetIn(m0, server)")
+
+	assert.EqualInt(t, 1, DelIn(m3, "server", "name").Get("server").(Map[string, any]).Len(), "DelIn(m3, server, name)")
+	assert.EqualInt(t, 1, DelIn(m3, "client", "name").Len(), "DelIn(m3, client, name)")
+	assert.EqualInt(t, 0, DelIn(m3).Len(), "DelIn(m3)")
+
+	m6 := UpdateIn(m0, func(v any, ok bool) any {
+		assert.Equal(t, false, ok, "UpdateIn(m0, a, b) present")
+		return 1
+	}, "a", "b")
+	v, _ = GetIn(m6, "a", "b")
+	assert.Equal(t, 1, v, "GetIn(m6, a, b)")
+}
+
+func TestPathInvalid(t *testing.T) {
+	defer func() {
+		assert.Equal(t, InvalidPath, recover(), "recover()")
+	}()
+	m := Map[string, any]{}.Set("name", "www")
+	SetIn(m, "x", "name", "first")
+	assert.Equal(t, false, true, "Unreachable")
+}
+
+func TestPathKeyType(t *testing.T) {
+	m := Map[string, any]{}.Set("ports", Vector[any]{}.Append(80))
+
+	// a key of the wrong type does not lead to a value
+	_, ok := GetIn(m, "ports", "0")
+	assert.Equal(t, false, ok, "GetIn(m, ports, \"0\")")
+	_, ok = GetIn(m, 0)
+	assert.Equal(t, false, ok, "GetIn(m, 0)")
+	v, _ := GetIn(DelIn(m, "ports", "0"), "ports", 0)
+	assert.Equal(t, 80, v, "DelIn(m, ports, \"0\")")
+	assert.EqualInt(t, 1, DelIn(m, 0).Len(), "DelIn(m, 0)")
+
+	// and is an invalid path for SetIn and UpdateIn
+	invalid := func(name string, f func()) {
+		defer func() {
+			assert.Equal(t, InvalidPath, recover(), "%s recover()", name)
+		}()
+		f()
+	}
+	invalid("SetIn(m, 443, ports, \"1\")", func() { SetIn(m, 443, "ports", "1") })
+	invalid("UpdateIn(m, f, 0)", func() { UpdateIn(m, func(any, bool) any { return 1 }, 0) })
+}
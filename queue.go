@@ -0,0 +1,136 @@
+package immutable
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// stream is a lazily evaluated cell of a singly linked list. The cell is
+// computed by eval the first time the stream is forced and then memoized,
+// so every version of a queue that shares the stream shares that work.
+type stream[T any] struct {
+	once sync.Once
+	eval func() *scell[T]
+	cell *scell[T]
+}
+
+type scell[T any] struct {
+	head T
+	tail *stream[T]
+}
+
+// cons returns an evaluated stream with the given head and tail.
+func cons[T any](head T, tail *stream[T]) *stream[T] {
+	return &stream[T]{cell: &scell[T]{head, tail}}
+}
+
+// force evaluates the stream when that did not happen yet and returns its
+// cell. It returns nil for the empty stream.
+func (s *stream[T]) force() *scell[T] {
+	if s == nil {
+		return nil
+	}
+	s.once.Do(func() {
+		if s.eval != nil {
+			s.cell = s.eval()
+			s.eval = nil
+		}
+	})
+	return s.cell
+}
+
+// rotate returns the lazy stream front ++ reverse(rear) ++ acc. The rear
+// list must be exactly one longer than the front stream. Forcing a cell of
+// the result takes O(1) time because it only forces the next cell of front.
+func rotate[T any](front *stream[T], rear *cell[T], acc *stream[T]) *stream[T] {
+	return &stream[T]{eval: func() *scell[T] {
+		c := front.force()
+		if c == nil {
+			return &scell[T]{rear.head, acc}
+		}
+		return &scell[T]{c.head, rotate(c.tail, rear.tail, cons(rear.head, acc))}
+	}}
+}
+
+// Queue is a persistent immutable first-in-first-out queue implemented as
+// Okasaki's real-time queue. Values are enqueued onto a rear list and
+// dequeued from a lazy front stream. When the rear list grows longer than the
+// front, it is rotated into the front lazily and every operation forces one
+// cell of that rotation through the schedule. Because forced cells are
+// memoized and shared by all versions, Enqueue, Peek and Dequeue take O(1)
+// time in the worst case, also when an old version of the queue is used
+// again.
+type Queue[T any] struct {
+	front    *stream[T]
+	rear     List[T]
+	schedule *stream[T]
+	len      int
+}
+
+// exec forces the next cell of the schedule, or starts a new rotation when
+// the schedule is empty because the rear list became longer than the front.
+func (a Queue[T]) exec() Queue[T] {
+	if c := a.schedule.force(); c != nil {
+		a.schedule = c.tail
+		return a
+	}
+	front := rotate(a.front, a.rear.cell, nil)
+	return Queue[T]{front, List[T]{}, front, a.len}
+}
+
+// Len returns the number of values in the queue.
+func (a Queue[T]) Len() int {
+	return a.len
+}
+
+// Enqueue returns a copy of the queue with the value added at the back.
+func (a Queue[T]) Enqueue(value T) Queue[T] {
+	return Queue[T]{a.front, a.rear.Push(value), a.schedule, a.len + 1}.exec()
+}
+
+// Peek returns the value at the front of the queue along with the value
+// true. It returns (zero, false) when the queue is empty.
+func (a Queue[T]) Peek() (T, bool) {
+	if c := a.front.force(); c != nil {
+		return c.head, true
+	}
+	var zero T
+	return zero, false
+}
+
+// Dequeue returns the value at the front and the queue without that value
+// along with the value true. It returns (zero, a, false) when the queue is
+// empty.
+func (a Queue[T]) Dequeue() (T, Queue[T], bool) {
+	c := a.front.force()
+	if c == nil {
+		var zero T
+		return zero, a, false
+	}
+	return c.head, Queue[T]{c.tail, a.rear, a.schedule, a.len - 1}.exec(), true
+}
+
+// Range calls the given function for every value from front to back.
+func (a Queue[T]) Range(f func(T) bool) {
+	for c := a.front.force(); c != nil; c = c.tail.force() {
+		if !f(c.head) {
+			return
+		}
+	}
+	a.rear.Reverse().Range(f)
+}
+
+// String returns a string representation of the values from front to back.
+func (a Queue[T]) String() string {
+	var b strings.Builder
+	b.WriteByte('[')
+	f := "%+v"
+	a.Range(func(v T) bool {
+		_, err := fmt.Fprintf(&b, f, v)
+		f = ", %+v"
+		return err == nil
+	})
+	b.WriteByte(']')
+	return b.String()
+}
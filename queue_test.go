@@ -0,0 +1,77 @@
+package immutable
+
+import (
+	"testing"
+)
+
+func TestQueue(t *testing.T) {
+	var q Queue[int]
+	for i := 1; i <= 3; i++ {
+		q = q.Enqueue(i)
+	}
+	assert.EqualInt(t, 3, q.Len(), "q.Len()")
+	assert.EqualString(t, "[1, 2, 3]", q.String(), "q.String()")
+
+	v, q1, ok := q.Dequeue()
+	assert.Equal(t, true, ok, "q.Dequeue()")
+	assert.EqualInt(t, 1, v, "q.Dequeue()")
+	q1 = q1.Enqueue(4)
+	assert.EqualString(t, "[2, 3, 4]", q1.String(), "q1.String()")
+	assert.EqualString(t, "[1, 2, 3]", q.String(), "q.String()")
+
+	var got []int
+	for {
+		var v int
+		var ok bool
+		if v, q1, ok = q1.Dequeue(); !ok {
+			break
+		}
+		got = append(got, v)
+		if v == 2 {
+			q1 = q1.Enqueue(5)
+		}
+	}
+	assert.EqualInt(t, 4, len(got), "len(got)")
+	for i, exp := range []int{2, 3, 4, 5} {
+		assert.EqualInt(t, exp, got[i], "got[%d]", i)
+	}
+	_, ok = q1.Peek()
+	assert.Equal(t, false, ok, "q1.Peek()")
+}
+
+func TestQueueReuse(t *testing.T) {
+	var q Queue[int]
+	var versions []Queue[int]
+	for i := 0; i < 100000; i++ {
+		q = q.Enqueue(i)
+		if i&(i+1) == 0 {
+			versions = append(versions, q)
+		}
+	}
+	// using any version again must not redo work proportional to the length
+	// of the queue, which would show up as allocations
+	for _, q := range append(versions, q) {
+		enqueue := testing.AllocsPerRun(100, func() { q.Enqueue(-1) })
+		assert.Equal(t, true, enqueue <= 4, "q.Enqueue() allocs %v for len %d", enqueue, q.Len())
+		dequeue := testing.AllocsPerRun(100, func() { q.Dequeue() })
+		assert.Equal(t, true, dequeue <= 4, "q.Dequeue() allocs %v for len %d", dequeue, q.Len())
+		v, rest, ok := q.Dequeue()
+		assert.Equal(t, true, ok, "q.Dequeue()")
+		assert.EqualInt(t, 0, v, "q.Dequeue()")
+		assert.EqualInt(t, q.Len()-1, rest.Len(), "rest.Len()")
+	}
+	n := 0
+	q.Range(func(v int) bool {
+		assert.EqualInt(t, n, v, "q.Range() #%d", n)
+		n++
+		return true
+	})
+	assert.EqualInt(t, 100000, n, "q.Range() count")
+	for i := 0; i < 100000; i++ {
+		v, rest, ok := q.Dequeue()
+		assert.Equal(t, true, ok, "q.Dequeue() #%d", i)
+		assert.EqualInt(t, i, v, "q.Dequeue() #%d", i)
+		q = rest
+	}
+	assert.EqualInt(t, 0, q.Len(), "q.Len()")
+}
@@ -20,7 +20,7 @@ func (a Set[K]) Depth() int {
 
 // Has returns true when an entry with the given key is present.
 func (a Set[K]) Has(key K) bool {
-	_, b := a.lookup(hash(key), 0, key)
+	_, b := a.lookup(hash(key), 0, key, equals[K])
 	return b
 }
 
@@ -45,10 +45,68 @@ func (a Set[K]) String() string {
 
 // Put returns a copy of the Set with the key added to it.
 func (a Set[K]) Put(key K) Set[K] {
-	return Set[K]{a.set(hash(key), 0, key, struct{}{})}
+	return Set[K]{a.set(hash(key), 0, key, struct{}{}, equals[K])}
 }
 
 // Del returns a copy of the Set with the key removed from it.
 func (a Set[K]) Del(key K) Set[K] {
-	return Set[K]{a.delete(hash(key), 0, key)}
+	return Set[K]{a.delete(hash(key), 0, key, equals[K])}
+}
+
+// SetX is a persistent immutable set with an external key marshal function.
+// The marshal function will map the key to a byte slice that is passed to
+// the internal hash function. The key itself is stored in the Set verbatim
+// and actually used in compare operations.
+type SetX[K comparable] struct {
+	amt[K, struct{}]
+	marshal func(any) ([]byte, error)
+}
+
+func SetWith[K comparable](marshal func(any) ([]byte, error)) SetX[K] {
+	return SetX[K]{amt[K, struct{}]{}, marshal}
+}
+
+func (a SetX[K]) hash(key K) uint32 {
+	k, e := a.marshal(key)
+	if e != nil {
+		panic(UnhashableKeyType)
+	}
+	return hash(k)
+}
+
+// Len returns the number of entries that are present.
+func (a SetX[K]) Len() int {
+	return a.len()
+}
+
+// Depth returns the number of levels in the Set.
+// Calling Depth on an empty amt returns 1.
+func (a SetX[K]) Depth() int {
+	return a.depth()
+}
+
+// Has returns true when an entry with the given key is present.
+func (a SetX[K]) Has(key K) bool {
+	_, b := a.lookup(a.hash(key), 0, key, equals[K])
+	return b
+}
+
+// Range calls the given function for every key present.
+func (a SetX[K]) Range(f func(K) bool) {
+	a.foreach(func(key K, _ struct{}) bool { return f(key) })
+}
+
+// String returns a string representation of the keys present.
+func (a SetX[K]) String() string {
+	return Set[K]{a.amt}.String()
+}
+
+// Put returns a copy of the Set with the key added to it.
+func (a SetX[K]) Put(key K) SetX[K] {
+	return SetX[K]{a.set(a.hash(key), 0, key, struct{}{}, equals[K]), a.marshal}
+}
+
+// Del returns a copy of the Set with the key removed from it.
+func (a SetX[K]) Del(key K) SetX[K] {
+	return SetX[K]{a.delete(a.hash(key), 0, key, equals[K]), a.marshal}
 }
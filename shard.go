@@ -0,0 +1,137 @@
+package immutable
+
+// shards splits the root of n into count amts holding contiguous ranges of
+// root slots. The ranges are chosen such that the number of entries in
+// every amt is roughly the same. Root entries are shared, not copied.
+func (n amt[K, V]) shards(count int) []amt[K, V] {
+	if count < 1 {
+		count = 1
+	}
+	shards := make([]amt[K, V], count)
+	total := n.len()
+	if total == 0 {
+		return shards
+	}
+	bits := n.bits
+	acc, start, prev := 0, 0, -1
+	for i, e := range n.entries {
+		size := 1
+		if a, ok := e.ref.(amt[K, V]); ok {
+			size = a.len()
+		}
+		s := (acc + size/2) * count / total
+		acc += size
+		if s != prev {
+			start, prev = i, s
+		}
+		bitpos := bits & -bits
+		bits &^= bitpos
+		shards[s].bits |= bitpos
+		shards[s].entries = n.entries[start : i+1 : i+1]
+	}
+	return shards
+}
+
+// graft returns an amt holding the root entries of n and o, which must
+// occupy disjoint root slots.
+func (n amt[K, V]) graft(o amt[K, V]) amt[K, V] {
+	entries := make([]*entry[V], 0, len(n.entries)+len(o.entries))
+	i, j := 0, 0
+	for bits := n.bits | o.bits; bits != 0; bits &= bits - 1 {
+		if present(n.bits, bits&-bits) {
+			entries = append(entries, n.entries[i])
+			i++
+		} else {
+			entries = append(entries, o.entries[j])
+			j++
+		}
+	}
+	return amt[K, V]{n.bits | o.bits, entries}
+}
+
+// join returns an amt holding the entries of n and of all others. An amt
+// whose root slots do not overlap with the slots occupied so far is grafted
+// onto the root, otherwise its entries are inserted one by one. For keys
+// present more than once the entry of the last amt is used. Keys are compared
+// with the equal function.
+func (n amt[K, V]) join(others []amt[K, V], equal func(K, K) bool) amt[K, V] {
+	for _, o := range others {
+		if n.bits&o.bits == 0 {
+			n = n.graft(o)
+			continue
+		}
+		o.each(func(e *entry[V]) bool {
+			n = n.set(e.prefix, 0, refKey[K](e.ref), e.value, equal)
+			return true
+		})
+	}
+	return n
+}
+
+// Shards splits the Map into n disjoint Maps holding roughly the same number
+// of entries. Every shard holds the entries of a contiguous range of slots
+// in the root of the Map, so the entries themselves are shared and not
+// copied. Some shards are empty when the root of the Map holds fewer than n
+// entries. A value of n less than 1 is treated as 1.
+func (a Map[K, V]) Shards(n int) []Map[K, V] {
+	amts := a.shards(n)
+	shards := make([]Map[K, V], len(amts))
+	for i, s := range amts {
+		shards[i] = Map[K, V]{s}
+	}
+	return shards
+}
+
+// Join returns a Map with the entries of a and all others. Joining the
+// shards returned by Shards takes time proportional to the number of shards.
+// Maps that share root slots with the Maps before them are merged entry by
+// entry instead. For keys present in more than one Map the value of the last
+// Map is used.
+func (a Map[K, V]) Join(others ...Map[K, V]) Map[K, V] {
+	amts := make([]amt[K, V], len(others))
+	for i, o := range others {
+		amts[i] = o.amt
+	}
+	return Map[K, V]{a.join(amts, equals[K])}
+}
+
+// Shards splits the Set into n disjoint Sets in the same way as Map.Shards.
+func (a Set[K]) Shards(n int) []Set[K] {
+	amts := a.shards(n)
+	shards := make([]Set[K], len(amts))
+	for i, s := range amts {
+		shards[i] = Set[K]{s}
+	}
+	return shards
+}
+
+// Join returns a Set with the keys of a and all others in the same way as
+// Map.Join.
+func (a Set[K]) Join(others ...Set[K]) Set[K] {
+	amts := make([]amt[K, struct{}], len(others))
+	for i, o := range others {
+		amts[i] = o.amt
+	}
+	return Set[K]{a.join(amts, equals[K])}
+}
+
+// Shards splits the Store into n disjoint Stores in the same way as
+// Map.Shards. The shards use the split function of the Store.
+func (a Store[D, K, V]) Shards(n int) []Store[D, K, V] {
+	amts := a.shards(n)
+	shards := make([]Store[D, K, V], len(amts))
+	for i, s := range amts {
+		shards[i] = Store[D, K, V]{s, a.split}
+	}
+	return shards
+}
+
+// Join returns a Store with the entries of a and all others in the same way
+// as Map.Join. The result uses the split function of a.
+func (a Store[D, K, V]) Join(others ...Store[D, K, V]) Store[D, K, V] {
+	amts := make([]amt[K, V], len(others))
+	for i, o := range others {
+		amts[i] = o.amt
+	}
+	return Store[D, K, V]{a.join(amts, equals[K]), a.split}
+}
@@ -0,0 +1,61 @@
+package immutable
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestShards(t *testing.T) {
+	var m Map[int, int]
+	for i := 0; i < 10000; i++ {
+		m = m.Set(i*7, i)
+	}
+	shards := m.Shards(4)
+	assert.EqualInt(t, 4, len(shards), "len(m.Shards(4))")
+	total := 0
+	for i, s := range shards {
+		total += s.Len()
+		assert.Equal(t, true, s.Len() > 2000 && s.Len() < 3000, "shards[%d].Len() %d roughly balanced", i, s.Len())
+		s.Range(func(k, v int) bool {
+			assert.EqualInt(t, v, m.Get(k), "shards[%d].Get(%d)", i, k)
+			return true
+		})
+	}
+	assert.EqualInt(t, m.Len(), total, "sum of shard lengths")
+	assert.Equal(t, m.entries[0], shards[0].entries[0], "shards share root entries")
+
+	j := shards[0].Join(shards[1:]...)
+	assert.Equal(t, true, reflect.DeepEqual(m.amt, j.amt), "Join(Shards()) equals m")
+	j = shards[3].Join(shards[1], shards[0], shards[2])
+	assert.Equal(t, true, reflect.DeepEqual(m.amt, j.amt), "Join(Shards()) out of order equals m")
+
+	assert.EqualInt(t, 8, len(Map[int, int]{}.Shards(8)), "len(empty.Shards(8))")
+	assert.EqualInt(t, 1, len(m.Shards(0)), "len(m.Shards(0))")
+	assert.EqualInt(t, 1, len(m.Shards(-1)), "len(m.Shards(-1))")
+	few := Map[int, int]{}.Set(1, 1).Set(2, 2).Shards(5)
+	assert.EqualInt(t, 2, few[0].Len()+few[1].Len()+few[2].Len()+few[3].Len()+few[4].Len(), "few shards total")
+}
+
+func TestJoinOverlap(t *testing.T) {
+	a := Map[string, int]{}.Set("a", 1).Set("b", 2)
+	b := Map[string, int]{}.Set("b", 3).Set("c", 4)
+	j := a.Join(b)
+	assert.EqualInt(t, 3, j.Len(), "j.Len()")
+	assert.EqualInt(t, 1, j.Get("a"), "j.Get(a)")
+	assert.EqualInt(t, 3, j.Get("b"), "j.Get(b)")
+	assert.EqualInt(t, 4, j.Get("c"), "j.Get(c)")
+
+	var s Set[int]
+	for i := 0; i < 100; i++ {
+		s = s.Put(i)
+	}
+	js := s.Shards(3)
+	assert.EqualInt(t, 100, js[2].Join(js[0], js[1], js[0]).Len(), "Set Join")
+
+	type composite struct{ name, value string }
+	st := StoreWith(func(data composite) (string, string) { return data.name, data.value }).
+		Put(composite{"first", "clown"}).Put(composite{"second", "joker"}).Put(composite{"third", "jester"})
+	ss := st.Shards(2)
+	assert.EqualInt(t, 3, ss[0].Len()+ss[1].Len(), "Store shards total")
+	assert.Equal(t, true, ss[1].Join(ss[0]).Has(composite{name: "second"}), "Store Join")
+}
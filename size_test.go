@@ -9,8 +9,8 @@ func TestSize(t *testing.T) {
 	const arch = int(2 - uint64(^uint(0))>>63)
 
 	ints0 := amt[uint8, uint8]{}
-	ints1 := ints0.set(hash(123), 0, 123, 42)
-	ints2 := ints1.set(hash(124), 0, 124, 69)
+	ints1 := ints0.set(hash(123), 0, 123, 42, equals[uint8])
+	ints2 := ints1.set(hash(124), 0, 124, 69, equals[uint8])
 	assert.EqualInt(t, 32/arch, SizeAMT(ints0), "SizeAMT(ints0)")
 	assert.EqualInt(t, 64/arch, SizeAMT(ints1), "SizeAMT(ints1)")
 	assert.EqualInt(t, 96/arch, SizeAMT(ints2), "SizeAMT(ints2)")
@@ -21,7 +21,7 @@ func TestSize(t *testing.T) {
 	assert.EqualInt(t, 40/arch, int(unsafe.Sizeof(MapX[any, any]{})), "unsafe.Sizeof(MapX{})")
 
 	t0 := &amt[any, string]{}
-	t1 := t0.set(0, 0, "Hello", "World!")
+	t1 := t0.set(0, 0, "Hello", "World!", equals[any])
 
 	assert.EqualInt(t, 8/arch, int(unsafe.Sizeof(t1.entries[0])), "unsafe.Sizeof(t1.entries[0])")
 	assert.EqualInt(t, 32/arch, SizeAMT(*t0), "t0.size()")
@@ -0,0 +1,191 @@
+package immutable
+
+import (
+	"runtime"
+	"sort"
+	"sync/atomic"
+)
+
+// clock is the global version clock of the software transactional memory.
+// Every transaction that writes refs advances it by one.
+var clock atomic.Uint64
+
+// refs is used to give every Ref a unique id that orders the locking of
+// refs during commit.
+var refs atomic.Uint64
+
+// conflict is the panic value that aborts a transaction that read a ref that
+// was changed after the transaction started. It is recovered by Atomically
+// to retry the transaction.
+type conflict struct{}
+
+// anyref is the part of a Ref that does not depend on the type of its value.
+type anyref interface {
+	id() uint64
+	word() *atomic.Uint64
+	store(value any)
+}
+
+// Ref is a reference to an immutable value such as a Map, Set or Store that
+// can be read and written inside transactions run by Atomically. Every ref
+// holds a versioned lock word. The lowest bit of the word is set while a
+// committing transaction holds the lock, the remaining bits hold the version
+// of the clock at which the value was last written.
+type Ref[T any] struct {
+	ident uint64
+	lock  atomic.Uint64
+	value atomic.Pointer[T]
+}
+
+// NewRef returns a new Ref holding the given value.
+func NewRef[T any](value T) *Ref[T] {
+	r := &Ref[T]{ident: refs.Add(1)}
+	r.value.Store(&value)
+	r.lock.Store(clock.Load() << 1)
+	return r
+}
+
+func (r *Ref[T]) id() uint64 {
+	return r.ident
+}
+
+func (r *Ref[T]) word() *atomic.Uint64 {
+	return &r.lock
+}
+
+func (r *Ref[T]) store(value any) {
+	r.value.Store(value.(*T))
+}
+
+// Load returns the current value of the ref outside of a transaction. Loads
+// of different refs are not isolated from concurrent transactions, use Get
+// inside Atomically to read several refs consistently.
+func (r *Ref[T]) Load() T {
+	if v := r.value.Load(); v != nil {
+		return *v
+	}
+	var zero T
+	return zero
+}
+
+// Get returns the value of the ref as seen by the transaction. This is the
+// value written by the transaction itself or otherwise the value the ref had
+// when the transaction started. When the ref was changed by another
+// transaction since then, Get aborts the transaction so it can be retried.
+func (r *Ref[T]) Get(tx *Tx) T {
+	if v, ok := tx.writes[r]; ok {
+		return *v.(*T)
+	}
+	w := r.lock.Load()
+	v := r.Load()
+	if w&1 != 0 || w>>1 > tx.version || r.lock.Load() != w {
+		panic(conflict{})
+	}
+	tx.reads[r] = struct{}{}
+	return v
+}
+
+// Set writes the value to the ref in the transaction. The value becomes
+// visible to other goroutines when the transaction commits.
+func (r *Ref[T]) Set(tx *Tx, value T) {
+	tx.writes[r] = &value
+}
+
+// Update sets the ref in the transaction to the result of calling f with the
+// value returned by Get.
+func (r *Ref[T]) Update(tx *Tx, f func(T) T) {
+	r.Set(tx, f(r.Get(tx)))
+}
+
+// Tx is a transaction that reads and writes refs. A transaction sees a
+// snapshot of all refs as they were when it started together with its own
+// writes.
+type Tx struct {
+	version uint64
+	reads   map[anyref]struct{}
+	writes  map[anyref]any // holds a *T, so a nil interface T can be written
+}
+
+// Atomically runs f in a transaction and commits the values written to refs
+// by f atomically when f returns nil. When f returns an error, the writes
+// are discarded and the error is returned. When another transaction changed
+// a ref read by f, f is called again in a new transaction, so f should have
+// no side effects other than reading and writing refs. Transactions do not
+// take locks while f runs, only refs written by f are locked briefly while
+// committing.
+func Atomically(f func(tx *Tx) error) error {
+	for {
+		tx := &Tx{
+			version: clock.Load(),
+			reads:   make(map[anyref]struct{}),
+			writes:  make(map[anyref]any),
+		}
+		if ok, err := tx.run(f); !ok {
+			runtime.Gosched()
+			continue
+		} else if err != nil {
+			return err
+		}
+		if tx.commit() {
+			return nil
+		}
+		runtime.Gosched()
+	}
+}
+
+// run calls f with the transaction and returns false when f was aborted by
+// a conflict.
+func (tx *Tx) run(f func(tx *Tx) error) (ok bool, err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			if _, retry := e.(conflict); !retry {
+				panic(e)
+			}
+			ok = false
+		}
+	}()
+	return true, f(tx)
+}
+
+// commit locks the refs written by the transaction in id order, validates
+// the refs read by the transaction and then stores the written values. It
+// returns false when a ref could not be locked or a ref read by the
+// transaction was changed by another transaction.
+func (tx *Tx) commit() bool {
+	if len(tx.writes) == 0 {
+		return true
+	}
+	locked := make([]anyref, 0, len(tx.writes))
+	for r := range tx.writes {
+		locked = append(locked, r)
+	}
+	sort.Slice(locked, func(i, j int) bool { return locked[i].id() < locked[j].id() })
+	unlock := func(refs []anyref) {
+		for _, r := range refs {
+			r.word().Store(r.word().Load() &^ 1)
+		}
+	}
+	for i, r := range locked {
+		w := r.word().Load()
+		if w&1 != 0 || !r.word().CompareAndSwap(w, w|1) {
+			unlock(locked[:i])
+			return false
+		}
+	}
+	version := clock.Add(1)
+	if version != tx.version+1 {
+		for r := range tx.reads {
+			w := r.word().Load()
+			_, mine := tx.writes[r]
+			if w&1 != 0 && !mine || w>>1 > tx.version {
+				unlock(locked)
+				return false
+			}
+		}
+	}
+	for _, r := range locked {
+		r.store(tx.writes[r])
+		r.word().Store(version << 1)
+	}
+	return true
+}
@@ -0,0 +1,74 @@
+package immutable
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestAtomically(t *testing.T) {
+	users := NewRef(Map[string, int]{})
+	sessions := NewRef(Map[int, string]{})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				id := i*100 + j
+				err := Atomically(func(tx *Tx) error {
+					users.Update(tx, func(m Map[string, int]) Map[string, int] {
+						return m.Set("count", m.Get("count")+1)
+					})
+					sessions.Update(tx, func(m Map[int, string]) Map[int, string] {
+						return m.Set(id, "user")
+					})
+					return nil
+				})
+				assert.Equal(t, nil, err, "Atomically")
+			}
+		}(i)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for j := 0; j < 100; j++ {
+			Atomically(func(tx *Tx) error {
+				count := users.Get(tx).Get("count")
+				assert.EqualInt(t, count, sessions.Get(tx).Len(), "sessions.Len()")
+				return nil
+			})
+		}
+	}()
+	wg.Wait()
+	assert.EqualInt(t, 400, users.Load().Get("count"), "users.Load().Get(count)")
+	assert.EqualInt(t, 400, sessions.Load().Len(), "sessions.Load().Len()")
+}
+
+func TestAtomicallyError(t *testing.T) {
+	failed := errors.New("failed")
+	r := NewRef(Set[string]{})
+	err := Atomically(func(tx *Tx) error {
+		r.Set(tx, r.Get(tx).Put("a"))
+		assert.Equal(t, true, r.Get(tx).Has("a"), "r.Get(tx).Has(a)")
+		return failed
+	})
+	assert.Equal(t, failed, err, "Atomically")
+	assert.EqualInt(t, 0, r.Load().Len(), "r.Load().Len()")
+}
+
+func TestRefNilInterface(t *testing.T) {
+	failed := errors.New("failed")
+	r := NewRef[error](failed)
+	err := Atomically(func(tx *Tx) error {
+		r.Set(tx, nil)
+		assert.Equal(t, nil, r.Get(tx), "r.Get(tx)")
+		return nil
+	})
+	assert.Equal(t, nil, err, "Atomically")
+	assert.Equal(t, nil, r.Load(), "r.Load()")
+	Atomically(func(tx *Tx) error {
+		assert.Equal(t, nil, r.Get(tx), "r.Get(tx)")
+		return nil
+	})
+}
@@ -24,7 +24,7 @@ func (a Store[D, K, V]) Depth() int {
 // Has returns true when an entry with the given key is present.
 func (a Store[D, K, V]) Has(data D) bool {
 	k, _ := a.split(data)
-	_, b := a.lookup(hash(k), 0, k)
+	_, b := a.lookup(hash(k), 0, k, equals[K])
 	return b
 }
 
@@ -32,7 +32,7 @@ func (a Store[D, K, V]) Has(data D) bool {
 // not present.
 func (a Store[D, K, V]) Get(data D) any {
 	k, _ := a.split(data)
-	v, _ := a.lookup(hash(k), 0, k)
+	v, _ := a.lookup(hash(k), 0, k, equals[K])
 	return v
 }
 
@@ -49,11 +49,81 @@ func (a Store[D, K, V]) String() string {
 // Put returns a copy of the Set with the key as part of the set.
 func (a Store[D, K, V]) Put(data D) Store[D, K, V] {
 	k, v := a.split(data)
-	return Store[D, K, V]{a.set(hash(k), 0, k, v), a.split}
+	return Store[D, K, V]{a.set(hash(k), 0, k, v, equals[K]), a.split}
 }
 
 // Del returns a copy of the Store with the key removed from the set.
 func (a Store[D, K, V]) Del(data D) Store[D, K, V] {
 	k, _ := a.split(data)
-	return Store[D, K, V]{a.delete(hash(k), 0, k), a.split}
+	return Store[D, K, V]{a.delete(hash(k), 0, k, equals[K]), a.split}
+}
+
+// StoreX is a Hash Array Mapped Trie with an external split function and an
+// external key marshal function. The marshal function will map the key
+// returned by the split function to a byte slice that is passed to the
+// internal hash function.
+type StoreX[D any, K comparable, V any] struct {
+	amt[K, V]
+	split   func(D) (K, V)
+	marshal func(any) ([]byte, error)
+}
+
+func StoreXWith[D any, K comparable, V any](splitter func(D) (K, V), marshal func(any) ([]byte, error)) StoreX[D, K, V] {
+	return StoreX[D, K, V]{amt[K, V]{}, splitter, marshal}
+}
+
+func (a StoreX[D, K, V]) hash(key K) uint32 {
+	k, e := a.marshal(key)
+	if e != nil {
+		panic(UnhashableKeyType)
+	}
+	return hash(k)
+}
+
+// Len returns the number of entries that are present.
+func (a StoreX[D, K, V]) Len() int {
+	return a.len()
+}
+
+// Depth returns the number of levels in the Hamt. Calling Depth on an empty
+// Hamt returns 1.
+func (a StoreX[D, K, V]) Depth() int {
+	return a.depth()
+}
+
+// Has returns true when an entry with the given key is present.
+func (a StoreX[D, K, V]) Has(data D) bool {
+	k, _ := a.split(data)
+	_, b := a.lookup(a.hash(k), 0, k, equals[K])
+	return b
+}
+
+// Get returns the value for the entry with the given key or nil when it is
+// not present.
+func (a StoreX[D, K, V]) Get(data D) any {
+	k, _ := a.split(data)
+	v, _ := a.lookup(a.hash(k), 0, k, equals[K])
+	return v
+}
+
+// Range calls the given function for every key,value pair present.
+func (a StoreX[D, K, V]) Range(f func(K, V) bool) {
+	a.foreach(f)
+}
+
+// String returns a string representation of the key,value pairs present.
+func (a StoreX[D, K, V]) String() string {
+	return a.string()
+}
+
+// Put returns a copy of the Store with the data stored in it.
+func (a StoreX[D, K, V]) Put(data D) StoreX[D, K, V] {
+	k, v := a.split(data)
+	return StoreX[D, K, V]{a.set(a.hash(k), 0, k, v, equals[K]), a.split, a.marshal}
+}
+
+// Del returns a copy of the Store with the entry for the data removed.
+func (a StoreX[D, K, V]) Del(data D) StoreX[D, K, V] {
+	k, _ := a.split(data)
+	return StoreX[D, K, V]{a.delete(a.hash(k), 0, k, equals[K]), a.split, a.marshal}
 }
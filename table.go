@@ -0,0 +1,101 @@
+package immutable
+
+// Table is a persistent immutable two-dimensional map that associates values
+// with (row, column) pairs. It keeps a row index and a column index in
+// lockstep, so both the values in a row and the values in a column can be
+// retrieved as a Map without copying.
+type Table[R, C comparable, V any] struct {
+	rows    Map[R, Map[C, V]]
+	columns Map[C, Map[R, V]]
+	len     int
+}
+
+// Len returns the number of cells that hold a value.
+func (a Table[R, C, V]) Len() int {
+	return a.len
+}
+
+// Lookup returns the value in the cell at row,column along with the value
+// true when the cell holds a value. Otherwise it returns (zero, false).
+func (a Table[R, C, V]) Lookup(row R, column C) (V, bool) {
+	return a.rows.Get(row).Lookup(column)
+}
+
+// Has returns true when the cell at row,column holds a value.
+func (a Table[R, C, V]) Has(row R, column C) bool {
+	return a.rows.Get(row).Has(column)
+}
+
+// Get returns the value in the cell at row,column or zero value when the cell
+// does not hold a value.
+func (a Table[R, C, V]) Get(row R, column C) V {
+	return a.rows.Get(row).Get(column)
+}
+
+// Row returns the values in the given row keyed by their column.
+func (a Table[R, C, V]) Row(row R) Map[C, V] {
+	return a.rows.Get(row)
+}
+
+// Column returns the values in the given column keyed by their row.
+func (a Table[R, C, V]) Column(column C) Map[R, V] {
+	return a.columns.Get(column)
+}
+
+// Range calls the given function for every row,column,value triple present.
+func (a Table[R, C, V]) Range(f func(R, C, V) bool) {
+	a.rows.Range(func(row R, columns Map[C, V]) bool {
+		more := true
+		columns.Range(func(column C, value V) bool {
+			more = f(row, column, value)
+			return more
+		})
+		return more
+	})
+}
+
+// String returns a string representation of the rows in the table.
+func (a Table[R, C, V]) String() string {
+	return a.rows.String()
+}
+
+// Set returns a copy of the Table with the value stored in the cell at
+// row,column.
+func (a Table[R, C, V]) Set(row R, column C, value V) Table[R, C, V] {
+	columns := a.rows.Get(row)
+	len := a.len
+	if !columns.Has(column) {
+		len++
+	}
+	return Table[R, C, V]{
+		a.rows.Set(row, columns.Set(column, value)),
+		a.columns.Set(column, a.columns.Get(column).Set(row, value)),
+		len,
+	}
+}
+
+// Del returns a copy of the Table with the value in the cell at row,column
+// removed. Rows and columns without values are removed from the indexes.
+func (a Table[R, C, V]) Del(row R, column C) Table[R, C, V] {
+	columns := a.rows.Get(row)
+	if !columns.Has(column) {
+		return a
+	}
+	if columns = columns.Del(column); columns.Len() == 0 {
+		a.rows = a.rows.Del(row)
+	} else {
+		a.rows = a.rows.Set(row, columns)
+	}
+	if rows := a.columns.Get(column).Del(row); rows.Len() == 0 {
+		a.columns = a.columns.Del(column)
+	} else {
+		a.columns = a.columns.Set(column, rows)
+	}
+	a.len--
+	return a
+}
+
+// Transpose returns the Table with rows and columns swapped in O(1) time.
+func (a Table[R, C, V]) Transpose() Table[C, R, V] {
+	return Table[C, R, V]{a.columns, a.rows, a.len}
+}
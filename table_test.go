@@ -0,0 +1,42 @@
+package immutable
+
+import (
+	"testing"
+)
+
+func TestTable(t *testing.T) {
+	var t0 Table[int, string, float64]
+	t1 := t0.Set(1, "a", 1.5).Set(1, "b", 2.5).Set(2, "a", 3.5).Set(1, "a", 4.5)
+
+	assert.EqualInt(t, 3, t1.Len(), "t1.Len()")
+	assert.Equal(t, 4.5, t1.Get(1, "a"), "t1.Get(1, a)")
+	assert.Equal(t, false, t1.Has(2, "b"), "t1.Has(2, b)")
+	assert.EqualInt(t, 2, t1.Row(1).Len(), "t1.Row(1).Len()")
+	assert.EqualInt(t, 2, t1.Column("a").Len(), "t1.Column(a).Len()")
+	assert.Equal(t, 4.5, t1.Column("a").Get(1), "t1.Column(a).Get(1)")
+
+	tr := t1.Transpose()
+	v, ok := tr.Lookup("b", 1)
+	assert.Equal(t, true, ok, "tr.Lookup(b, 1)")
+	assert.Equal(t, 2.5, v, "tr.Lookup(b, 1)")
+	assert.EqualInt(t, 2, tr.Row("a").Len(), "tr.Row(a).Len()")
+
+	t2 := t1.Del(2, "a").Del(7, "x")
+	assert.EqualInt(t, 2, t2.Len(), "t2.Len()")
+	assert.EqualInt(t, 1, t2.Column("a").Len(), "t2.Column(a).Len()")
+	assert.EqualInt(t, 1, t2.rows.Len(), "t2.rows.Len()")
+	assert.EqualInt(t, 3, t1.Len(), "t1.Len()")
+
+	t3 := t2.Del(1, "a").Del(1, "b")
+	assert.EqualInt(t, 0, t3.Len(), "t3.Len()")
+	assert.EqualInt(t, 0, t3.rows.Len(), "t3.rows.Len()")
+	assert.EqualInt(t, 0, t3.columns.Len(), "t3.columns.Len()")
+
+	sum := 0.0
+	t1.Range(func(r int, c string, v float64) bool {
+		sum += v
+		return true
+	})
+	assert.Equal(t, 10.5, sum, "t1.Range()")
+	assert.EqualString(t, "{1:{a:1.5}}", t0.Set(1, "a", 1.5).String(), "String()")
+}
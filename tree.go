@@ -0,0 +1,363 @@
+package immutable
+
+// node is a node of a persistent weight balanced binary search tree. A nil
+// *node is the empty tree. Nodes are never modified after they have been
+// created, operations return a new tree that shares all untouched nodes with
+// the tree it was derived from.
+type node[K, V any] struct {
+	left, right *node[K, V]
+	key         K
+	value       V
+	size        int
+}
+
+// delta and ratio are the balance parameters for the weight balanced tree.
+// A tree is balanced when the size of either subtree (plus 1) is at most
+// delta times the size of the other subtree (plus 1).
+const delta = 3
+const ratio = 2
+
+func mknode[K, V any](key K, value V, left, right *node[K, V]) *node[K, V] {
+	return &node[K, V]{left, right, key, value, left.len() + right.len() + 1}
+}
+
+func (n *node[K, V]) len() int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+func (n *node[K, V]) depth() int {
+	if n == nil {
+		return 0
+	}
+	l, r := n.left.depth(), n.right.depth()
+	if l > r {
+		return 1 + l
+	}
+	return 1 + r
+}
+
+// balance returns a balanced tree for key,value and the subtrees left and
+// right under the condition that the subtrees were balanced before a single
+// insert or delete on either of them.
+func balance[K, V any](key K, value V, left, right *node[K, V]) *node[K, V] {
+	l, r := left.len()+1, right.len()+1
+	if r > delta*l {
+		rl, rr := right.left, right.right
+		if rl.len()+1 < ratio*(rr.len()+1) {
+			return mknode(right.key, right.value, mknode(key, value, left, rl), rr)
+		}
+		return mknode(rl.key, rl.value, mknode(key, value, left, rl.left), mknode(right.key, right.value, rl.right, rr))
+	}
+	if l > delta*r {
+		ll, lr := left.left, left.right
+		if lr.len()+1 < ratio*(ll.len()+1) {
+			return mknode(left.key, left.value, ll, mknode(key, value, lr, right))
+		}
+		return mknode(lr.key, lr.value, mknode(left.key, left.value, ll, lr.left), mknode(key, value, lr.right, right))
+	}
+	return mknode(key, value, left, right)
+}
+
+// link returns a balanced tree for key,value and the subtrees left and right
+// of arbitrary size. All keys in left must be smaller than key and all keys
+// in right must be larger than key.
+func link[K, V any](key K, value V, left, right *node[K, V]) *node[K, V] {
+	switch {
+	case left == nil:
+		return right.insertMin(key, value)
+	case right == nil:
+		return left.insertMax(key, value)
+	case delta*(left.size+1) < right.size+1:
+		return balance(right.key, right.value, link(key, value, left, right.left), right.right)
+	case delta*(right.size+1) < left.size+1:
+		return balance(left.key, left.value, left.left, link(key, value, left.right, right))
+	}
+	return mknode(key, value, left, right)
+}
+
+// merge returns a balanced tree holding the entries of left and right. All
+// keys in left must be smaller than the keys in right.
+func merge[K, V any](left, right *node[K, V]) *node[K, V] {
+	switch {
+	case left == nil:
+		return right
+	case right == nil:
+		return left
+	case delta*(left.size+1) < right.size+1:
+		return balance(right.key, right.value, merge(left, right.left), right.right)
+	case delta*(right.size+1) < left.size+1:
+		return balance(left.key, left.value, left.left, merge(left.right, right))
+	case left.size > right.size:
+		max := left.max()
+		return balance(max.key, max.value, left.deleteMax(), right)
+	default:
+		min := right.min()
+		return balance(min.key, min.value, left, right.deleteMin())
+	}
+}
+
+func (n *node[K, V]) insertMin(key K, value V) *node[K, V] {
+	if n == nil {
+		return &node[K, V]{key: key, value: value, size: 1}
+	}
+	return balance(n.key, n.value, n.left.insertMin(key, value), n.right)
+}
+
+func (n *node[K, V]) insertMax(key K, value V) *node[K, V] {
+	if n == nil {
+		return &node[K, V]{key: key, value: value, size: 1}
+	}
+	return balance(n.key, n.value, n.left, n.right.insertMax(key, value))
+}
+
+func (n *node[K, V]) deleteMin() *node[K, V] {
+	if n.left == nil {
+		return n.right
+	}
+	return balance(n.key, n.value, n.left.deleteMin(), n.right)
+}
+
+func (n *node[K, V]) deleteMax() *node[K, V] {
+	if n.right == nil {
+		return n.left
+	}
+	return balance(n.key, n.value, n.left, n.right.deleteMax())
+}
+
+func (n *node[K, V]) min() *node[K, V] {
+	if n != nil {
+		for n.left != nil {
+			n = n.left
+		}
+	}
+	return n
+}
+
+func (n *node[K, V]) max() *node[K, V] {
+	if n != nil {
+		for n.right != nil {
+			n = n.right
+		}
+	}
+	return n
+}
+
+func (n *node[K, V]) lookup(key K, compare func(K, K) int) *node[K, V] {
+	for n != nil {
+		c := compare(key, n.key)
+		switch {
+		case c < 0:
+			n = n.left
+		case c > 0:
+			n = n.right
+		default:
+			return n
+		}
+	}
+	return nil
+}
+
+// floor returns the node with the largest key smaller than (or equal to when
+// inclusive is true) the given key.
+func (n *node[K, V]) floor(key K, inclusive bool, compare func(K, K) int) *node[K, V] {
+	var found *node[K, V]
+	for n != nil {
+		c := compare(key, n.key)
+		switch {
+		case c == 0 && inclusive:
+			return n
+		case c <= 0:
+			n = n.left
+		default:
+			found, n = n, n.right
+		}
+	}
+	return found
+}
+
+// ceiling returns the node with the smallest key larger than (or equal to
+// when inclusive is true) the given key.
+func (n *node[K, V]) ceiling(key K, inclusive bool, compare func(K, K) int) *node[K, V] {
+	var found *node[K, V]
+	for n != nil {
+		c := compare(key, n.key)
+		switch {
+		case c == 0 && inclusive:
+			return n
+		case c >= 0:
+			n = n.right
+		default:
+			found, n = n, n.left
+		}
+	}
+	return found
+}
+
+// rank returns the number of keys smaller than the given key.
+func (n *node[K, V]) rank(key K, compare func(K, K) int) int {
+	rank := 0
+	for n != nil {
+		c := compare(key, n.key)
+		switch {
+		case c < 0:
+			n = n.left
+		case c > 0:
+			rank += n.left.len() + 1
+			n = n.right
+		default:
+			return rank + n.left.len()
+		}
+	}
+	return rank
+}
+
+// at returns the node at the given index in key order or nil when the index
+// is out of range.
+func (n *node[K, V]) at(index int) *node[K, V] {
+	for n != nil {
+		l := n.left.len()
+		switch {
+		case index < l:
+			n = n.left
+		case index > l:
+			index -= l + 1
+			n = n.right
+		default:
+			return n
+		}
+	}
+	return nil
+}
+
+func (n *node[K, V]) insert(key K, value V, compare func(K, K) int) *node[K, V] {
+	if n == nil {
+		return &node[K, V]{key: key, value: value, size: 1}
+	}
+	c := compare(key, n.key)
+	switch {
+	case c < 0:
+		return balance(n.key, n.value, n.left.insert(key, value, compare), n.right)
+	case c > 0:
+		return balance(n.key, n.value, n.left, n.right.insert(key, value, compare))
+	}
+	return &node[K, V]{n.left, n.right, key, value, n.size}
+}
+
+// delete returns the tree with the key removed. The tree is returned
+// unmodified when the key is not present.
+func (n *node[K, V]) delete(key K, compare func(K, K) int) *node[K, V] {
+	if n == nil {
+		return nil
+	}
+	c := compare(key, n.key)
+	switch {
+	case c < 0:
+		if left := n.left.delete(key, compare); left != n.left {
+			return balance(n.key, n.value, left, n.right)
+		}
+		return n
+	case c > 0:
+		if right := n.right.delete(key, compare); right != n.right {
+			return balance(n.key, n.value, n.left, right)
+		}
+		return n
+	}
+	return merge(n.left, n.right)
+}
+
+// split returns the tree of keys smaller than key, the node holding key (or
+// nil when not present) and the tree of keys larger than key.
+func (n *node[K, V]) split(key K, compare func(K, K) int) (*node[K, V], *node[K, V], *node[K, V]) {
+	if n == nil {
+		return nil, nil, nil
+	}
+	c := compare(key, n.key)
+	switch {
+	case c < 0:
+		l, found, r := n.left.split(key, compare)
+		return l, found, link(n.key, n.value, r, n.right)
+	case c > 0:
+		l, found, r := n.right.split(key, compare)
+		return link(n.key, n.value, n.left, l), found, r
+	}
+	return n.left, n, n.right
+}
+
+// union returns a tree with the entries of both trees. For keys present in
+// both trees the entry of n is used.
+func (n *node[K, V]) union(o *node[K, V], compare func(K, K) int) *node[K, V] {
+	if n == nil {
+		return o
+	}
+	if o == nil {
+		return n
+	}
+	l, _, r := o.split(n.key, compare)
+	return link(n.key, n.value, n.left.union(l, compare), n.right.union(r, compare))
+}
+
+// intersection returns a tree with the entries of n whose keys are also
+// present in o.
+func (n *node[K, V]) intersection(o *node[K, V], compare func(K, K) int) *node[K, V] {
+	if n == nil || o == nil {
+		return nil
+	}
+	l, found, r := o.split(n.key, compare)
+	left, right := n.left.intersection(l, compare), n.right.intersection(r, compare)
+	if found != nil {
+		return link(n.key, n.value, left, right)
+	}
+	return merge(left, right)
+}
+
+// difference returns a tree with the entries of n whose keys are not present
+// in o.
+func (n *node[K, V]) difference(o *node[K, V], compare func(K, K) int) *node[K, V] {
+	if n == nil || o == nil {
+		return n
+	}
+	l, _, r := n.split(o.key, compare)
+	return merge(l.difference(o.left, compare), r.difference(o.right, compare))
+}
+
+func (n *node[K, V]) foreach(f func(K, V) bool) bool {
+	for n != nil {
+		if !n.left.foreach(f) || !f(n.key, n.value) {
+			return false
+		}
+		n = n.right
+	}
+	return true
+}
+
+func (n *node[K, V]) reverse(f func(K, V) bool) bool {
+	for n != nil {
+		if !n.right.reverse(f) || !f(n.key, n.value) {
+			return false
+		}
+		n = n.left
+	}
+	return true
+}
+
+// between calls f in key order for every entry with lo <= key < hi.
+func (n *node[K, V]) between(lo, hi K, compare func(K, K) int, f func(K, V) bool) bool {
+	for n != nil {
+		if compare(n.key, lo) < 0 {
+			n = n.right
+			continue
+		}
+		if compare(n.key, hi) >= 0 {
+			n = n.left
+			continue
+		}
+		if !n.left.between(lo, hi, compare, f) || !f(n.key, n.value) {
+			return false
+		}
+		n = n.right
+	}
+	return true
+}
@@ -0,0 +1,304 @@
+package immutable
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// tnode is a node of a persistent radix tree. The prefix of a node is the
+// part of the key that leads from its parent to the node. The edges of a
+// node are sorted on the first byte of their prefix and no two edges share
+// the same first byte. A node that does not hold a value has at least 2
+// edges.
+type tnode[V any] struct {
+	prefix string
+	edges  []*tnode[V]
+	value  V
+	leaf   bool
+	size   int
+}
+
+func (n *tnode[V]) len() int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+func commonPrefix(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
+
+// edge returns the index of the edge starting with byte c or the index where
+// such an edge should be inserted along with the value false.
+func (n *tnode[V]) edge(c byte) (int, bool) {
+	i := sort.Search(len(n.edges), func(i int) bool { return n.edges[i].prefix[0] >= c })
+	return i, i < len(n.edges) && n.edges[i].prefix[0] == c
+}
+
+// with returns a copy of the node with the edge at index i replaced by e.
+// The edge is removed when e is nil and inserted when insert is true.
+func (n *tnode[V]) with(i int, e *tnode[V], insert bool) *tnode[V] {
+	var edges []*tnode[V]
+	switch {
+	case insert:
+		edges = make([]*tnode[V], len(n.edges)+1)
+		copy(edges, n.edges[:i])
+		copy(edges[i+1:], n.edges[i:])
+		edges[i] = e
+	case e == nil:
+		edges = make([]*tnode[V], len(n.edges)-1)
+		copy(edges, n.edges[:i])
+		copy(edges[i:], n.edges[i+1:])
+	default:
+		edges = clone(n.edges)
+		edges[i] = e
+	}
+	size := n.size + e.len()
+	if !insert {
+		size -= n.edges[i].size
+	}
+	return (&tnode[V]{n.prefix, edges, n.value, n.leaf, size}).compact()
+}
+
+// compact removes a node without a value that has less than 2 edges by
+// merging it with its only edge.
+func (n *tnode[V]) compact() *tnode[V] {
+	if n.leaf {
+		return n
+	}
+	switch len(n.edges) {
+	case 0:
+		return nil
+	case 1:
+		e := n.edges[0]
+		return &tnode[V]{n.prefix + e.prefix, e.edges, e.value, e.leaf, e.size}
+	}
+	return n
+}
+
+func (n *tnode[V]) lookup(key string) *tnode[V] {
+	for n != nil && strings.HasPrefix(key, n.prefix) {
+		if key = key[len(n.prefix):]; key == "" {
+			if n.leaf {
+				return n
+			}
+			return nil
+		}
+		i, ok := n.edge(key[0])
+		if !ok {
+			return nil
+		}
+		n = n.edges[i]
+	}
+	return nil
+}
+
+func (n *tnode[V]) insert(key string, value V) *tnode[V] {
+	if n == nil {
+		return &tnode[V]{prefix: key, value: value, leaf: true, size: 1}
+	}
+	if c := commonPrefix(key, n.prefix); c < len(n.prefix) {
+		// split the node so its prefix becomes a prefix of the key
+		e := &tnode[V]{n.prefix[c:], n.edges, n.value, n.leaf, n.size}
+		n = &tnode[V]{prefix: n.prefix[:c], edges: []*tnode[V]{e}, size: n.size}
+	}
+	rest := key[len(n.prefix):]
+	if rest == "" {
+		size := n.size
+		if !n.leaf {
+			size++
+		}
+		return &tnode[V]{n.prefix, n.edges, value, true, size}
+	}
+	i, ok := n.edge(rest[0])
+	if !ok {
+		return n.with(i, (*tnode[V])(nil).insert(rest, value), true)
+	}
+	return n.with(i, n.edges[i].insert(rest, value), false)
+}
+
+func (n *tnode[V]) delete(key string) *tnode[V] {
+	if n == nil || !strings.HasPrefix(key, n.prefix) {
+		return n
+	}
+	rest := key[len(n.prefix):]
+	if rest == "" {
+		if !n.leaf {
+			return n
+		}
+		return (&tnode[V]{prefix: n.prefix, edges: n.edges, size: n.size - 1}).compact()
+	}
+	i, ok := n.edge(rest[0])
+	if !ok {
+		return n
+	}
+	e := n.edges[i].delete(rest)
+	if e == n.edges[i] {
+		return n
+	}
+	return n.with(i, e, false)
+}
+
+// deletePrefix returns the node with all keys starting with prefix removed.
+func (n *tnode[V]) deletePrefix(prefix string) *tnode[V] {
+	if n == nil || strings.HasPrefix(n.prefix, prefix) {
+		return nil
+	}
+	if !strings.HasPrefix(prefix, n.prefix) {
+		return n
+	}
+	rest := prefix[len(n.prefix):]
+	i, ok := n.edge(rest[0])
+	if !ok {
+		return n
+	}
+	e := n.edges[i].deletePrefix(rest)
+	if e == n.edges[i] {
+		return n
+	}
+	return n.with(i, e, false)
+}
+
+// foreach calls f in key order for every value in the subtree of the node,
+// where path is the part of the key leading up to the node.
+func (n *tnode[V]) foreach(path string, f func(string, V) bool) bool {
+	if n == nil {
+		return true
+	}
+	path += n.prefix
+	if n.leaf && !f(path, n.value) {
+		return false
+	}
+	for _, e := range n.edges {
+		if !e.foreach(path, f) {
+			return false
+		}
+	}
+	return true
+}
+
+// Trie is a persistent immutable radix tree with string keys. Unlike Map it
+// keeps its keys in lexicographic order and supports prefix queries. Nodes
+// along a path with a single branch are compressed into a single node and
+// modifications copy only the nodes on the path to the modified key.
+type Trie[V any] struct{ root *tnode[V] }
+
+// Len returns the number of entries that are present.
+func (a Trie[V]) Len() int {
+	return a.root.len()
+}
+
+// Lookup returns the value of an entry associated with a given key along with
+// the value true when the key is present. Otherwise it returns (zero, false).
+func (a Trie[V]) Lookup(key string) (V, bool) {
+	if n := a.root.lookup(key); n != nil {
+		return n.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Has returns true when an entry with the given key is present.
+func (a Trie[V]) Has(key string) bool {
+	return a.root.lookup(key) != nil
+}
+
+// Get returns the value for the entry with the given key or zero value
+// when it is not present.
+func (a Trie[V]) Get(key string) V {
+	v, _ := a.Lookup(key)
+	return v
+}
+
+// Range calls the given function for every key,value pair present in
+// lexicographic key order.
+func (a Trie[V]) Range(f func(string, V) bool) {
+	a.root.foreach("", f)
+}
+
+// WalkPrefix calls the given function in lexicographic key order for every
+// key,value pair with a key that starts with prefix.
+func (a Trie[V]) WalkPrefix(prefix string, f func(string, V) bool) {
+	n, path := a.root, ""
+	for n != nil {
+		if strings.HasPrefix(n.prefix, prefix) {
+			n.foreach(path, f)
+			return
+		}
+		if !strings.HasPrefix(prefix, n.prefix) {
+			return
+		}
+		path += n.prefix
+		prefix = prefix[len(n.prefix):]
+		i, ok := n.edge(prefix[0])
+		if !ok {
+			return
+		}
+		n = n.edges[i]
+	}
+}
+
+// LongestPrefix returns the entry with the longest key that is a prefix of s
+// along with the value true. It returns ("", zero, false) when no key is a
+// prefix of s.
+func (a Trie[V]) LongestPrefix(s string) (string, V, bool) {
+	var match *tnode[V]
+	var key string
+	n, path := a.root, ""
+	for n != nil && strings.HasPrefix(s, n.prefix) {
+		path += n.prefix
+		s = s[len(n.prefix):]
+		if n.leaf {
+			match, key = n, path
+		}
+		if s == "" {
+			break
+		}
+		i, ok := n.edge(s[0])
+		if !ok {
+			break
+		}
+		n = n.edges[i]
+	}
+	if match == nil {
+		var zero V
+		return "", zero, false
+	}
+	return key, match.value, true
+}
+
+// String returns a string representation of the key,value pairs present.
+func (a Trie[V]) String() string {
+	var b strings.Builder
+	b.WriteByte('{')
+	f := "%+v:%+v"
+	a.root.foreach("", func(k string, v V) bool {
+		_, err := fmt.Fprintf(&b, f, k, v)
+		f = ", %+v:%+v"
+		return err == nil
+	})
+	b.WriteByte('}')
+	return b.String()
+}
+
+// Set returns a copy of the Trie with the given key,value pair inserted.
+func (a Trie[V]) Set(key string, value V) Trie[V] {
+	return Trie[V]{a.root.insert(key, value)}
+}
+
+// Del returns a copy of the Trie with the entry for the key removed.
+func (a Trie[V]) Del(key string) Trie[V] {
+	return Trie[V]{a.root.delete(key)}
+}
+
+// DeletePrefix returns a copy of the Trie with all entries removed whose key
+// starts with prefix.
+func (a Trie[V]) DeletePrefix(prefix string) Trie[V] {
+	return Trie[V]{a.root.deletePrefix(prefix)}
+}
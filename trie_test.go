@@ -0,0 +1,113 @@
+package immutable
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// compacted returns true when every node without a value has at least 2
+// edges and the sizes are correct.
+func compacted[V any](n *tnode[V], root bool) bool {
+	if n == nil {
+		return true
+	}
+	if !root && n.prefix == "" || !n.leaf && len(n.edges) < 2 {
+		return false
+	}
+	size := 0
+	if n.leaf {
+		size++
+	}
+	for _, e := range n.edges {
+		if !compacted(e, false) {
+			return false
+		}
+		size += e.size
+	}
+	return n.size == size
+}
+
+func TestTrie(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	var tr Trie[int]
+	ref := map[string]int{}
+	for i := 0; i < 3000; i++ {
+		b := make([]byte, rnd.Intn(5))
+		for j := range b {
+			b[j] = "abc"[rnd.Intn(3)]
+		}
+		k := string(b)
+		if rnd.Intn(3) == 0 {
+			tr = tr.Del(k)
+			delete(ref, k)
+		} else {
+			tr = tr.Set(k, i)
+			ref[k] = i
+		}
+		if !compacted(tr.root, true) {
+			t.Fatalf("malformed trie after op #%d", i)
+		}
+	}
+	assert.EqualInt(t, len(ref), tr.Len(), "tr.Len()")
+
+	keys := make([]string, 0, len(ref))
+	for k := range ref {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	i := 0
+	tr.Range(func(k string, v int) bool {
+		assert.EqualString(t, keys[i], k, "tr.Range() #%d", i)
+		assert.EqualInt(t, ref[k], v, "tr.Range() #%d", i)
+		i++
+		return true
+	})
+	assert.EqualInt(t, len(keys), i, "tr.Range() count")
+
+	for _, p := range []string{"", "a", "ab", "cab", "abcab"} {
+		var exp, got []string
+		for _, k := range keys {
+			if strings.HasPrefix(k, p) {
+				exp = append(exp, k)
+			}
+		}
+		tr.WalkPrefix(p, func(k string, _ int) bool {
+			got = append(got, k)
+			return true
+		})
+		assert.EqualString(t, strings.Join(exp, ","), strings.Join(got, ","), "tr.WalkPrefix(%q)", p)
+
+		d := tr.DeletePrefix(p)
+		assert.Equal(t, true, compacted(d.root, true), "compacted(tr.DeletePrefix(%q))", p)
+		assert.EqualInt(t, len(keys)-len(exp), d.Len(), "tr.DeletePrefix(%q).Len()", p)
+		for _, k := range exp {
+			assert.Equal(t, false, d.Has(k), "tr.DeletePrefix(%q).Has(%q)", p, k)
+		}
+	}
+}
+
+func TestTrieLongestPrefix(t *testing.T) {
+	var tr Trie[string]
+	tr = tr.Set("/", "root").Set("/api", "api").Set("/api/v1", "v1").Set("/apix", "apix")
+
+	tests := []struct{ path, key, value string }{
+		{"/api/v1/users", "/api/v1", "v1"},
+		{"/api/v2", "/api", "api"},
+		{"/ap", "/", "root"},
+		{"/apix", "/apix", "apix"},
+	}
+	for _, test := range tests {
+		k, v, ok := tr.LongestPrefix(test.path)
+		assert.Equal(t, true, ok, "tr.LongestPrefix(%q)", test.path)
+		assert.EqualString(t, test.key, k, "tr.LongestPrefix(%q) key", test.path)
+		assert.EqualString(t, test.value, v, "tr.LongestPrefix(%q) value", test.path)
+	}
+	_, _, ok := tr.LongestPrefix("api")
+	assert.Equal(t, false, ok, "tr.LongestPrefix(api)")
+
+	assert.EqualString(t, "{/:root, /api:api, /api/v1:v1, /apix:apix}", tr.String(), "tr.String()")
+	assert.EqualString(t, "{/:root, /apix:apix}", tr.Del("/api").Del("/api/v1").String(), "tr.Del().String()")
+	assert.EqualString(t, "api", tr.Get("/api"), "tr.Get(/api)")
+}
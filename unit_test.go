@@ -1,6 +1,7 @@
 package immutable
 
 import (
+	"encoding/json"
 	"testing"
 )
 
@@ -227,6 +228,31 @@ func TestRange(t *testing.T) {
 	assert.EqualInt(t, 2, c2, "t2.Range()")
 }
 
+func TestRangeStop(t *testing.T) {
+	// keys 0, 32 and 64 share the first slot and end up in a nested amt
+	var m Map[int, int]
+	var s Set[int]
+	x := MapWith[int, int](json.Marshal)
+	y := StoreWith(func(k int) (int, int) { return k, k })
+	for _, k := range []int{0, 32, 64, 1, 2} {
+		m, s, x, y = m.Set(k, k), s.Put(k), x.Set(k, k), y.Put(k)
+	}
+	assert.EqualInt(t, 2, m.Depth(), "m.Depth()")
+
+	n := 0
+	m.Range(func(int, int) bool { n++; return false })
+	assert.EqualInt(t, 1, n, "m.Range()")
+	n = 0
+	s.Range(func(int) bool { n++; return false })
+	assert.EqualInt(t, 1, n, "s.Range()")
+	n = 0
+	x.Range(func(int, int) bool { n++; return false })
+	assert.EqualInt(t, 1, n, "x.Range()")
+	n = 0
+	y.Range(func(int, int) bool { n++; return false })
+	assert.EqualInt(t, 1, n, "y.Range()")
+}
+
 func TestSet(t *testing.T) {
 	var s0 Set[string]
 
@@ -641,3 +667,88 @@ var assert = struct {
 		}
 	},
 }
+
+func TestMapH(t *testing.T) {
+	type key struct{ ids []int }
+	equal := func(a, b key) bool {
+		if len(a.ids) != len(b.ids) {
+			return false
+		}
+		for i := range a.ids {
+			if a.ids[i] != b.ids[i] {
+				return false
+			}
+		}
+		return true
+	}
+	// hash only on the length of ids to force collisions
+	m0 := MapWithHasher[key, int](func(k key) uint64 { return uint64(len(k.ids)) << 32 }, equal)
+
+	m1 := m0.Set(key{[]int{1}}, 1).Set(key{[]int{2}}, 2).Set(key{[]int{1, 2}}, 12).Set(key{[]int{1}}, 11)
+	assert.EqualInt(t, 3, m1.Len(), "m1.Len()")
+	assert.EqualInt(t, 11, m1.Get(key{[]int{1}}), "m1.Get({1})")
+	assert.EqualInt(t, 2, m1.Get(key{[]int{2}}), "m1.Get({2})")
+	assert.EqualInt(t, 12, m1.Get(key{[]int{1, 2}}), "m1.Get({1, 2})")
+	assert.Equal(t, false, m1.Has(key{[]int{3}}), "m1.Has({3})")
+
+	m2 := m1.Del(key{[]int{1}}).Del(key{[]int{3}})
+	assert.EqualInt(t, 2, m2.Len(), "m2.Len()")
+	assert.Equal(t, false, m2.Has(key{[]int{1}}), "m2.Has({1})")
+	assert.Equal(t, true, m2.Has(key{[]int{2}}), "m2.Has({2})")
+	assert.Equal(t, true, m1.Has(key{[]int{1}}), "m1.Has({1})")
+
+	n := 0
+	m2.Range(func(k key, v int) bool {
+		n++
+		return true
+	})
+	assert.EqualInt(t, 2, n, "m2.Range()")
+}
+
+func TestMapNilKey(t *testing.T) {
+	m := MapWith[any, int](json.Marshal).Set(nil, 1).Set(nil, 2).Set("a", 3)
+	assert.EqualInt(t, 2, m.Len(), "m.Len()")
+	assert.EqualInt(t, 2, m.Get(nil), "m.Get(nil)")
+	n := 0
+	m.Range(func(k any, v int) bool {
+		n++
+		return true
+	})
+	assert.EqualInt(t, 2, n, "m.Range()")
+	assert.EqualInt(t, 1, m.Del(nil).Len(), "m.Del(nil).Len()")
+
+	h := MapWithHasher[error, int](func(error) uint64 { return 0 }, func(a, b error) bool { return a == b })
+	h = h.Set(nil, 1).Set(nil, 2)
+	assert.EqualInt(t, 1, h.Len(), "h.Len()")
+	assert.EqualInt(t, 2, h.Get(nil), "h.Get(nil)")
+}
+
+func TestSetX(t *testing.T) {
+	type key struct{ A, B int }
+	s0 := SetWith[key](json.Marshal)
+	s1 := s0.Put(key{1, 2}).Put(key{3, 4}).Put(key{1, 2})
+	assert.EqualInt(t, 2, s1.Len(), "s1.Len()")
+	assert.Equal(t, true, s1.Has(key{1, 2}), "s1.Has({1, 2})")
+	assert.Equal(t, false, s1.Has(key{2, 1}), "s1.Has({2, 1})")
+	s2 := s1.Del(key{1, 2})
+	assert.Equal(t, false, s2.Has(key{1, 2}), "s2.Has({1, 2})")
+	assert.EqualString(t, "{{A:3 B:4}}", s2.String(), "s2.String()")
+}
+
+func TestStoreX(t *testing.T) {
+	type key struct{ first, last string }
+	type person struct {
+		name key
+		age  int
+	}
+	x0 := StoreXWith(func(p person) (key, int) { return p.name, p.age }, func(k any) ([]byte, error) {
+		return []byte(k.(key).first + " " + k.(key).last), nil
+	})
+	x1 := x0.Put(person{key{"Ada", "Lovelace"}, 36}).Put(person{key{"Alan", "Turing"}, 41})
+	assert.EqualInt(t, 2, x1.Len(), "x1.Len()")
+	assert.Equal(t, true, x1.Has(person{name: key{"Ada", "Lovelace"}}), "x1.Has(Ada)")
+	assert.Equal(t, 41, x1.Get(person{name: key{"Alan", "Turing"}}), "x1.Get(Alan)")
+	x2 := x1.Del(person{name: key{"Ada", "Lovelace"}})
+	assert.Equal(t, false, x2.Has(person{name: key{"Ada", "Lovelace"}}), "x2.Has(Ada)")
+	assert.EqualInt(t, 1, x2.Len(), "x2.Len()")
+}
@@ -0,0 +1,444 @@
+package immutable
+
+import (
+	"fmt"
+	"strings"
+)
+
+// branching is the number of children of a vector node, the same as the
+// number of entries an amt node can hold.
+const branching = 1 << nextlevel
+
+// vnode is a node of a relaxed radix balanced (RRB) tree. A leaf holds up to
+// 32 values and an internal node holds up to 32 children. An internal node
+// without sizes is dense, i.e. all its children except the last one are
+// completely filled, so the child holding an index is found by radix
+// indexing. A relaxed node has sizes with the cumulative number of values in
+// its children.
+type vnode[T any] struct {
+	children []*vnode[T]
+	values   []T
+	sizes    []int
+	count    int
+}
+
+func vleaf[T any](values []T) *vnode[T] {
+	return &vnode[T]{values: values, count: len(values)}
+}
+
+// vinternal returns an internal node at the given shift for the children.
+// A child of a node at shift can hold at most 1<<shift values.
+func vinternal[T any](children []*vnode[T], shift uint8) *vnode[T] {
+	n := &vnode[T]{children: children}
+	dense := true
+	for i, c := range children {
+		n.count += c.count
+		if i < len(children)-1 && c.count != 1<<shift {
+			dense = false
+		}
+	}
+	if !dense {
+		n.sizes = make([]int, len(children))
+		sum := 0
+		for i, c := range children {
+			sum += c.count
+			n.sizes[i] = sum
+		}
+	}
+	return n
+}
+
+// vpath returns a node at the given shift that holds only the leaf.
+func vpath[T any](shift uint8, leaf *vnode[T]) *vnode[T] {
+	if shift == 0 {
+		return leaf
+	}
+	return vinternal([]*vnode[T]{vpath(shift-nextlevel, leaf)}, shift)
+}
+
+func clone[T any](s []T) []T {
+	return append(make([]T, 0, len(s)+1), s...)
+}
+
+func (n *vnode[T]) len() int {
+	if n == nil {
+		return 0
+	}
+	return n.count
+}
+
+// width returns the number of slots in use by the node.
+func (n *vnode[T]) width() int {
+	if n.children == nil {
+		return len(n.values)
+	}
+	return len(n.children)
+}
+
+// index returns the index of the child that holds the value at index i and
+// the index of that value inside the child.
+func (n *vnode[T]) index(shift uint8, i int) (int, int) {
+	c := i >> shift
+	if n.sizes == nil {
+		return c, i - c<<shift
+	}
+	for n.sizes[c] <= i {
+		c++
+	}
+	if c > 0 {
+		i -= n.sizes[c-1]
+	}
+	return c, i
+}
+
+func (n *vnode[T]) get(shift uint8, i int) T {
+	for ; shift > 0; shift -= nextlevel {
+		var c int
+		c, i = n.index(shift, i)
+		n = n.children[c]
+	}
+	return n.values[i]
+}
+
+func (n *vnode[T]) set(shift uint8, i int, value T) *vnode[T] {
+	if shift == 0 {
+		values := clone(n.values)
+		values[i] = value
+		return vleaf(values)
+	}
+	c, i := n.index(shift, i)
+	children := clone(n.children)
+	children[c] = children[c].set(shift-nextlevel, i, value)
+	return &vnode[T]{children, nil, n.sizes, n.count}
+}
+
+// push returns a copy of the node with the leaf added as its rightmost leaf
+// or nil when there is no room left in the node.
+func (n *vnode[T]) push(shift uint8, leaf *vnode[T]) *vnode[T] {
+	last := len(n.children) - 1
+	if shift > nextlevel {
+		if c := n.children[last].push(shift-nextlevel, leaf); c != nil {
+			children := clone(n.children)
+			children[last] = c
+			return vinternal(children, shift)
+		}
+	}
+	if len(n.children) == branching {
+		return nil
+	}
+	return vinternal(append(clone(n.children), vpath(shift-nextlevel, leaf)), shift)
+}
+
+// pop returns the rightmost leaf of the node and a copy of the node without
+// that leaf, the copy is nil when the node held only the leaf.
+func (n *vnode[T]) pop(shift uint8) (*vnode[T], *vnode[T]) {
+	if shift == 0 {
+		return n, nil
+	}
+	last := len(n.children) - 1
+	leaf, c := n.children[last].pop(shift - nextlevel)
+	if c != nil {
+		children := clone(n.children)
+		children[last] = c
+		return leaf, vinternal(children, shift)
+	}
+	if last == 0 {
+		return leaf, nil
+	}
+	return leaf, vinternal(clone(n.children[:last]), shift)
+}
+
+// take returns a copy of the node holding only the first k values.
+func (n *vnode[T]) take(shift uint8, k int) *vnode[T] {
+	if k == n.count {
+		return n
+	}
+	if shift == 0 {
+		return vleaf(n.values[:k:k])
+	}
+	c, i := n.index(shift, k-1)
+	children := clone(n.children[:c+1])
+	children[c] = children[c].take(shift-nextlevel, i+1)
+	return vinternal(children, shift)
+}
+
+// drop returns a copy of the node without the first k values.
+func (n *vnode[T]) drop(shift uint8, k int) *vnode[T] {
+	if k == 0 {
+		return n
+	}
+	if shift == 0 {
+		return vleaf(n.values[k:])
+	}
+	c, i := n.index(shift, k)
+	children := clone(n.children[c:])
+	children[0] = children[0].drop(shift-nextlevel, i)
+	return vinternal(children, shift)
+}
+
+func (n *vnode[T]) foreach(shift uint8, i int, f func(int, T) bool) (int, bool) {
+	if shift == 0 {
+		for _, v := range n.values {
+			if !f(i, v) {
+				return i, false
+			}
+			i++
+		}
+		return i, true
+	}
+	for _, c := range n.children {
+		var ok bool
+		if i, ok = c.foreach(shift-nextlevel, i, f); !ok {
+			return i, false
+		}
+	}
+	return i, true
+}
+
+// vconcat concatenates two nodes at the same shift and returns one node when
+// the result fits in a single node or two nodes otherwise.
+func vconcat[T any](l, r *vnode[T], shift uint8) []*vnode[T] {
+	if shift == 0 {
+		if l.count+r.count > branching {
+			return []*vnode[T]{l, r}
+		}
+		values := make([]T, 0, l.count+r.count)
+		values = append(append(values, l.values...), r.values...)
+		return []*vnode[T]{vleaf(values)}
+	}
+	last := len(l.children) - 1
+	mid := vconcat(l.children[last], r.children[0], shift-nextlevel)
+	children := make([]*vnode[T], 0, last+len(mid)+len(r.children)-1)
+	children = append(children, l.children[:last]...)
+	children = append(children, mid...)
+	children = append(children, r.children[1:]...)
+	children = vrebalance(children, shift-nextlevel)
+	if len(children) <= branching {
+		return []*vnode[T]{vinternal(children, shift)}
+	}
+	return []*vnode[T]{vinternal(children[:branching], shift), vinternal(children[branching:], shift)}
+}
+
+// vrebalance repacks the nodes at the given shift into completely filled
+// nodes when they use more than 2 nodes above the optimal number of nodes
+// needed to hold their slots. This keeps the height of a tree built by
+// repeated concatenation logarithmic.
+func vrebalance[T any](nodes []*vnode[T], shift uint8) []*vnode[T] {
+	slots := 0
+	for _, n := range nodes {
+		slots += n.width()
+	}
+	if len(nodes) <= (slots+branching-1)/branching+2 {
+		return nodes
+	}
+	packed := make([]*vnode[T], 0, (slots+branching-1)/branching)
+	if shift == 0 {
+		values := make([]T, 0, slots)
+		for _, n := range nodes {
+			values = append(values, n.values...)
+		}
+		for len(values) > branching {
+			packed = append(packed, vleaf(values[:branching:branching]))
+			values = values[branching:]
+		}
+		return append(packed, vleaf(values))
+	}
+	children := make([]*vnode[T], 0, slots)
+	for _, n := range nodes {
+		children = append(children, n.children...)
+	}
+	for len(children) > branching {
+		packed = append(packed, vinternal(children[:branching:branching], shift))
+		children = children[branching:]
+	}
+	return append(packed, vinternal(children, shift))
+}
+
+// Vector is a persistent immutable sequence of values implemented as a
+// relaxed radix balanced (RRB) tree with the same 32-way branching as the
+// amt used by Map. Values are appended to a tail buffer that is pushed into
+// the tree once it is full, which makes Append amortized O(1). Get, Set, Pop,
+// Slice and Concat take O(log n) time.
+type Vector[T any] struct {
+	root  *vnode[T]
+	shift uint8
+	tail  []T
+}
+
+// mkvector returns a vector for root and tail, removing levels from the tree
+// that only have a single child and moving the last leaf into the tail when
+// the tail is empty.
+func mkvector[T any](root *vnode[T], shift uint8, tail []T) Vector[T] {
+	if root != nil && len(tail) == 0 {
+		var leaf *vnode[T]
+		leaf, root = root.pop(shift)
+		tail = leaf.values
+	}
+	for root != nil && shift > 0 && len(root.children) == 1 {
+		root = root.children[0]
+		shift -= nextlevel
+	}
+	if root == nil {
+		shift = 0
+	}
+	return Vector[T]{root, shift, tail}
+}
+
+// pushLeaf returns the root and shift of the tree with the leaf added as its
+// rightmost leaf.
+func (a Vector[T]) pushLeaf(leaf *vnode[T]) (*vnode[T], uint8) {
+	switch {
+	case a.root == nil:
+		return leaf, 0
+	case a.shift == 0:
+		return vinternal([]*vnode[T]{a.root, leaf}, nextlevel), nextlevel
+	}
+	if root := a.root.push(a.shift, leaf); root != nil {
+		return root, a.shift
+	}
+	shift := a.shift + nextlevel
+	return vinternal([]*vnode[T]{a.root, vpath(a.shift, leaf)}, shift), shift
+}
+
+// Len returns the number of values in the vector.
+func (a Vector[T]) Len() int {
+	return a.root.len() + len(a.tail)
+}
+
+// Get returns the value at the given index. It panics with IndexOutOfRange
+// when the index is not in the range [0, Len()).
+func (a Vector[T]) Get(index int) T {
+	if index < 0 || index >= a.Len() {
+		panic(IndexOutOfRange)
+	}
+	if n := a.root.len(); index >= n {
+		return a.tail[index-n]
+	}
+	return a.root.get(a.shift, index)
+}
+
+// Set returns a copy of the vector with the value at the given index
+// replaced. It panics with IndexOutOfRange when the index is not in the range
+// [0, Len()).
+func (a Vector[T]) Set(index int, value T) Vector[T] {
+	if index < 0 || index >= a.Len() {
+		panic(IndexOutOfRange)
+	}
+	if n := a.root.len(); index >= n {
+		tail := clone(a.tail)
+		tail[index-n] = value
+		return Vector[T]{a.root, a.shift, tail}
+	}
+	return Vector[T]{a.root.set(a.shift, index, value), a.shift, a.tail}
+}
+
+// Append returns a copy of the vector with the values added at the end.
+func (a Vector[T]) Append(values ...T) Vector[T] {
+	for _, value := range values {
+		if len(a.tail) < branching {
+			a.tail = append(clone(a.tail), value)
+			continue
+		}
+		a.root, a.shift = a.pushLeaf(vleaf(a.tail))
+		a.tail = []T{value}
+	}
+	return a
+}
+
+// Pop returns the last value and a copy of the vector without that value
+// along with the value true. It returns (zero, a, false) when the vector is
+// empty.
+func (a Vector[T]) Pop() (T, Vector[T], bool) {
+	if len(a.tail) == 0 {
+		var zero T
+		return zero, a, false
+	}
+	last := len(a.tail) - 1
+	return a.tail[last], mkvector(a.root, a.shift, a.tail[:last:last]), true
+}
+
+// Slice returns a vector with the values in the half open range [from, to).
+// It panics with IndexOutOfRange unless 0 <= from <= to <= Len().
+func (a Vector[T]) Slice(from, to int) Vector[T] {
+	if from < 0 || to < from || to > a.Len() {
+		panic(IndexOutOfRange)
+	}
+	if from == to {
+		return Vector[T]{}
+	}
+	n := a.root.len()
+	var tail []T
+	if to > n {
+		if from > n {
+			tail = a.tail[from-n : to-n : to-n]
+		} else {
+			tail = a.tail[: to-n : to-n]
+		}
+	}
+	var root *vnode[T]
+	if from < n {
+		if to < n {
+			root = a.root.take(a.shift, to)
+		} else {
+			root = a.root
+		}
+		root = root.drop(a.shift, from)
+	}
+	return mkvector(root, a.shift, tail)
+}
+
+// Concat returns a vector with the values of a followed by the values of b.
+func (a Vector[T]) Concat(b Vector[T]) Vector[T] {
+	switch {
+	case a.Len() == 0:
+		return b
+	case b.Len() == 0:
+		return a
+	case b.root == nil:
+		return a.Append(b.tail...)
+	}
+	lroot, lshift := a.pushLeaf(vleaf(a.tail))
+	rroot, rshift := b.root, b.shift
+	for ; lshift < rshift; lshift += nextlevel {
+		lroot = vinternal([]*vnode[T]{lroot}, lshift+nextlevel)
+	}
+	for ; rshift < lshift; rshift += nextlevel {
+		rroot = vinternal([]*vnode[T]{rroot}, rshift+nextlevel)
+	}
+	nodes := vconcat(lroot, rroot, lshift)
+	if len(nodes) == 1 {
+		return mkvector(nodes[0], lshift, b.tail)
+	}
+	return mkvector(vinternal(nodes, lshift+nextlevel), lshift+nextlevel, b.tail)
+}
+
+// Range calls the given function for every index,value pair in order.
+func (a Vector[T]) Range(f func(int, T) bool) {
+	i := 0
+	if a.root != nil {
+		var ok bool
+		if i, ok = a.root.foreach(a.shift, 0, f); !ok {
+			return
+		}
+	}
+	for _, v := range a.tail {
+		if !f(i, v) {
+			return
+		}
+		i++
+	}
+}
+
+// String returns a string representation of the values in the vector.
+func (a Vector[T]) String() string {
+	var b strings.Builder
+	b.WriteByte('[')
+	f := "%+v"
+	a.Range(func(_ int, v T) bool {
+		_, err := fmt.Fprintf(&b, f, v)
+		f = ", %+v"
+		return err == nil
+	})
+	b.WriteByte(']')
+	return b.String()
+}
@@ -0,0 +1,147 @@
+package immutable
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// wellformed returns true when every node in the tree has the correct count
+// and is marked dense only when radix indexing finds the right child.
+func wellformed[T any](n *vnode[T], shift uint8) bool {
+	if shift == 0 {
+		return n.children == nil && n.count == len(n.values) && len(n.values) <= branching
+	}
+	if len(n.children) == 0 || len(n.children) > branching {
+		return false
+	}
+	count := 0
+	for i, c := range n.children {
+		if !wellformed(c, shift-nextlevel) {
+			return false
+		}
+		if n.sizes == nil && i < len(n.children)-1 && c.count != 1<<shift {
+			return false
+		}
+		count += c.count
+		if n.sizes != nil && n.sizes[i] != count {
+			return false
+		}
+	}
+	return n.count == count
+}
+
+func checkVector(t *testing.T, v Vector[int], exp []int, op string) {
+	t.Helper()
+	if v.root != nil && !wellformed(v.root, v.shift) {
+		t.Fatalf("%s: malformed tree", op)
+	}
+	if len(exp) > 0 && len(v.tail) == 0 {
+		t.Fatalf("%s: empty tail", op)
+	}
+	if v.Len() != len(exp) {
+		t.Fatalf("%s: Len() expected %d got %d", op, len(exp), v.Len())
+	}
+	for i, e := range exp {
+		if g := v.Get(i); g != e {
+			t.Fatalf("%s: Get(%d) expected %d got %d", op, i, e, g)
+		}
+	}
+	count := 0
+	v.Range(func(i, value int) bool {
+		if value != exp[i] {
+			t.Fatalf("%s: Range() #%d expected %d got %d", op, i, exp[i], value)
+		}
+		count++
+		return true
+	})
+	assert.EqualInt(t, len(exp), count, "%s: Range() count", op)
+}
+
+func TestVector(t *testing.T) {
+	var v0 Vector[int]
+	ref := []int{}
+	v := v0
+	for i := 0; i < 5000; i++ {
+		v = v.Append(i)
+		ref = append(ref, i)
+	}
+	checkVector(t, v, ref, "Append")
+	checkVector(t, v0, nil, "v0")
+
+	w := v.Set(1234, -1).Set(4999, -2)
+	ref2 := append([]int(nil), ref...)
+	ref2[1234], ref2[4999] = -1, -2
+	checkVector(t, w, ref2, "Set")
+	checkVector(t, v, ref, "Set original")
+
+	for i := 0; i < 1100; i++ {
+		var x int
+		var ok bool
+		x, w, ok = w.Pop()
+		assert.Equal(t, true, ok, "w.Pop() ok")
+		assert.EqualInt(t, ref2[len(ref2)-1], x, "w.Pop()")
+		ref2 = ref2[:len(ref2)-1]
+	}
+	checkVector(t, w, ref2, "Pop")
+
+	checkVector(t, v.Slice(33, 4000), ref[33:4000], "Slice(33, 4000)")
+	checkVector(t, v.Slice(4990, 5000), ref[4990:5000], "Slice(4990, 5000)")
+	checkVector(t, v.Slice(1000, 1000), nil, "Slice(1000, 1000)")
+	checkVector(t, v.Slice(0, 1), ref[0:1], "Slice(0, 1)")
+
+	assert.EqualString(t, "[1, 2, 3]", v.Slice(1, 4).String(), "v.Slice(1, 4).String()")
+}
+
+func TestVectorConcat(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	random := func() (Vector[int], []int) {
+		var v Vector[int]
+		var ref []int
+		n := rnd.Intn(2000)
+		for i := 0; i < n; i++ {
+			x := rnd.Int()
+			v, ref = v.Append(x), append(ref, x)
+		}
+		if from := rnd.Intn(len(ref) + 1); from < len(ref) {
+			to := from + rnd.Intn(len(ref)-from+1)
+			v, ref = v.Slice(from, to), ref[from:to]
+		}
+		return v, ref
+	}
+	v, ref := random()
+	for i := 0; i < 200; i++ {
+		w, wref := random()
+		if rnd.Intn(2) == 0 {
+			v, ref = v.Concat(w), append(append([]int(nil), ref...), wref...)
+		} else {
+			v, ref = w.Concat(v), append(append([]int(nil), wref...), ref...)
+		}
+		checkVector(t, v, ref, "Concat")
+		if len(ref) > 0 && rnd.Intn(4) == 0 {
+			from := rnd.Intn(len(ref))
+			to := from + rnd.Intn(len(ref)-from)
+			v, ref = v.Slice(from, to), ref[from:to]
+			checkVector(t, v, ref, "Slice")
+		}
+	}
+
+	// prepending single values must keep the tree shallow
+	var p Vector[int]
+	for i := 0; i < 10000; i++ {
+		p = Vector[int]{}.Append(i).Concat(p)
+	}
+	for i := 0; i < 10000; i++ {
+		if p.Get(i) != 9999-i {
+			t.Fatalf("p.Get(%d) expected %d got %d", i, 9999-i, p.Get(i))
+		}
+	}
+	assert.Equal(t, true, p.shift <= 3*nextlevel, "p.shift %d", p.shift)
+}
+
+func TestVectorIndexOutOfRange(t *testing.T) {
+	defer func() {
+		assert.Equal(t, IndexOutOfRange, recover(), "recover()")
+	}()
+	Vector[int]{}.Append(1, 2, 3).Get(3)
+	assert.Equal(t, false, true, "Unreachable")
+}